@@ -200,7 +200,7 @@ func (b *buffer) string(search byte, token bool) error {
 		}
 		b.last = b.state
 	}
-	return b.errorSymbol()
+	return b.errorEOF()
 }
 
 func (b *buffer) null() error {
@@ -0,0 +1,162 @@
+package ajson
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonicalize returns the canonical form of the node by JSON Canonicalization Scheme (RFC 8785):
+//
+//   - whitespaces are removed;
+//   - keys of objects are sorted by their UTF-16 code units;
+//   - numbers are formatted the ECMAScript way, e.g.: `1e+30`, `0.002`, `4.5`;
+//   - strings are escaped minimally: only `"`, `\` and control characters.
+//
+// Both parsed and changed nodes are supported. NaN and Infinity values can't be canonicalized.
+func Canonicalize(node *Node) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := canonicalize(buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func canonicalize(buf *bytes.Buffer, node *Node) error {
+	if node == nil {
+		return errorUnparsed()
+	}
+	if !node.dirty && !node.ready() {
+		return errorUnparsed()
+	}
+	switch node.Type() {
+	case Null:
+		buf.Write(_null)
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return err
+		} else if value {
+			buf.Write(_true)
+		} else {
+			buf.Write(_false)
+		}
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return errorRequest("number %s can't be canonicalized: %s", node.Source(), err)
+		}
+		number, err := canonicalNumber(value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case String:
+		value, err := node.GetString()
+		if err != nil {
+			return err
+		}
+		canonicalString(buf, value)
+	case Array:
+		buf.WriteByte(bracketL)
+		for i, child := range node.Inheritors() {
+			if i != 0 {
+				buf.WriteByte(coma)
+			}
+			if err := canonicalize(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(bracketR)
+	case Object:
+		keys := node.Keys()
+		units := make(map[string][]uint16, len(keys))
+		for _, key := range keys {
+			units[key] = utf16.Encode([]rune(key))
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(units[keys[i]], units[keys[j]])
+		})
+		buf.WriteByte(bracesL)
+		for i, key := range keys {
+			if i != 0 {
+				buf.WriteByte(coma)
+			}
+			canonicalString(buf, key)
+			buf.WriteByte(colon)
+			if err := canonicalize(buf, node.children[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(bracesR)
+	}
+	return nil
+}
+
+// canonicalNumber formats the number by the ECMAScript rules (ECMA-262, Number.prototype.toString)
+func canonicalNumber(value float64) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", errorRequest("number %v can't be canonicalized", value)
+	}
+	if value == 0 {
+		return "0", nil // also for -0
+	}
+	return formatFloat(value, 64), nil
+}
+
+// formatFloat formats the number the ECMAScript way, same as encoding/json does
+func formatFloat(value float64, bits int) string {
+	abs := math.Abs(value)
+	if abs == 0 || (bits == 64 && abs >= 1e-6 && abs < 1e21) || (bits == 32 && float32(abs) >= 1e-6 && float32(abs) < 1e21) {
+		return strconv.FormatFloat(value, 'f', -1, bits)
+	}
+	result := strconv.FormatFloat(value, 'e', -1, bits)
+	// exponent should be without leading zeros: `1e-7` instead of `1e-07`
+	index := strings.IndexByte(result, 'e')
+	exponent := strings.TrimLeft(result[index+2:], "0")
+	return result[:index+2] + exponent
+}
+
+// canonicalString writes the quoted string with the minimal escaping
+func canonicalString(buf *bytes.Buffer, value string) {
+	buf.WriteByte(quotes)
+	for _, c := range value {
+		switch c {
+		case '"', '\\':
+			buf.WriteByte(backslash)
+			buf.WriteRune(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xF])
+			} else {
+				buf.WriteRune(c)
+			}
+		}
+	}
+	buf.WriteByte(quotes)
+}
+
+// lessUTF16 compares strings by their UTF-16 code units
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
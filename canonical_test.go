@@ -0,0 +1,191 @@
+package ajson
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// Test vectors of RFC 8785: https://github.com/cyberphone/json-canonicalization/tree/master/testdata
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "arrays",
+			input: `[
+  56,
+  {
+    "d": true,
+    "10": null,
+    "1": [ ]
+  }
+]`,
+			expected: `[56,{"1":[],"10":null,"d":true}]`,
+		},
+		{
+			name: "french",
+			input: `{
+  "peach": "This sorting order",
+  "péché": "is wrong according to French",
+  "pêche": "but canonicalization MUST",
+  "sin":   "ignore locale"
+}`,
+			expected: `{"peach":"This sorting order","péché":"is wrong according to French","pêche":"but canonicalization MUST","sin":"ignore locale"}`,
+		},
+		{
+			name: "structures",
+			input: `{
+  "1": {"f": {"f": "hi","F": 5} ,"\n": 56.0},
+  "10": { },
+  "": "empty",
+  "a": { },
+  "111": [ {"e": "yes","E": "no" } ],
+  "A": { }
+}`,
+			expected: `{"":"empty","1":{"\n":56,"f":{"F":5,"f":"hi"}},"10":{},"111":[{"E":"no","e":"yes"}],"A":{},"a":{}}`,
+		},
+		{
+			name: "unicode",
+			input: `{
+  "Unnormalized Unicode":"A\u030a"
+}`,
+			expected: "{\"Unnormalized Unicode\":\"A\u030a\"}",
+		},
+		{
+			name: "values",
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50,
+              2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name: "weird",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\u000a": "Newline",
+  "1": "One",
+  "\u0080": "Control\u007f",
+  "\ud83d\ude02": "Smiley",
+  "\u00f6": "Latin Small Letter O With Diaeresis",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "</script>": "Browser Challenge"
+}`,
+			expected: "{\"\\n\":\"Newline\",\"\\r\":\"Carriage Return\",\"1\":\"One\",\"</script>\":\"Browser Challenge\"," +
+				"\"\u0080\":\"Control\u007f\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\"," +
+				"\"\U0001F602\":\"Smiley\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Canonicalize(Must(Unmarshal([]byte(test.input))))
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("Canonicalize() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+}
+
+// Test vectors of RFC 8785, Appendix B
+func TestCanonicalize_numbers(t *testing.T) {
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{
+		{bits: 0x0000000000000000, expected: "0"},
+		{bits: 0x8000000000000000, expected: "0"},
+		{bits: 0x0000000000000001, expected: "5e-324"},
+		{bits: 0x8000000000000001, expected: "-5e-324"},
+		{bits: 0x7fefffffffffffff, expected: "1.7976931348623157e+308"},
+		{bits: 0xffefffffffffffff, expected: "-1.7976931348623157e+308"},
+		{bits: 0x4340000000000000, expected: "9007199254740992"},
+		{bits: 0xc340000000000000, expected: "-9007199254740992"},
+		{bits: 0x4430000000000000, expected: "295147905179352830000"},
+		{bits: 0x44b52d02c7e14af5, expected: "9.999999999999997e+22"},
+		{bits: 0x44b52d02c7e14af6, expected: "1e+23"},
+		{bits: 0x44b52d02c7e14af7, expected: "1.0000000000000001e+23"},
+		{bits: 0x444b1ae4d6e2ef4e, expected: "999999999999999700000"},
+		{bits: 0x444b1ae4d6e2ef4f, expected: "999999999999999900000"},
+		{bits: 0x444b1ae4d6e2ef50, expected: "1e+21"},
+		{bits: 0x3eb0c6f7a0b5ed8c, expected: "9.999999999999997e-7"},
+		{bits: 0x3eb0c6f7a0b5ed8d, expected: "0.000001"},
+		{bits: 0x41b3de4355555553, expected: "333333333.3333332"},
+		{bits: 0x41b3de4355555554, expected: "333333333.33333325"},
+		{bits: 0x41b3de4355555555, expected: "333333333.3333333"},
+		{bits: 0x41b3de4355555556, expected: "333333333.3333334"},
+		{bits: 0x41b3de4355555557, expected: "333333333.33333343"},
+		{bits: 0xbecbf647612f3696, expected: "-0.0000033333333333333333"},
+		{bits: 0x43143ff3c1cb0959, expected: "1424953923781206.2"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			result, err := Canonicalize(NumericNode("", math.Float64frombits(test.bits)))
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("Canonicalize() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := Canonicalize(NumericNode("", value)); err == nil {
+			t.Errorf("Canonicalize(%v) expected error", value)
+		}
+	}
+	for _, value := range []string{`1e400`, `[-1e400]`} {
+		_, err := Canonicalize(Must(Unmarshal([]byte(value))))
+		if e, ok := err.(Error); !ok || e.Type != WrongRequest {
+			t.Errorf("Canonicalize(%s) error = %v, expected WrongRequest", value, err)
+		}
+	}
+}
+
+func TestCanonicalize_dirty(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"b": [1.50, "x"], "a": {"d": 1e2}}`)))
+	if err := root.AppendObject("\u00e9", StringNode("", "tab\tand\u0001")); err != nil {
+		t.Fatalf("AppendObject() error = %v", err)
+	}
+	if err := root.MustKey("b").AppendArray(NumericNode("", 1e21), BoolNode("", false)); err != nil {
+		t.Fatalf("AppendArray() error = %v", err)
+	}
+	result, err := Canonicalize(root)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	expected := `{"a":{"d":100},"b":[1.5,"x",1e+21,false],"é":"tab\tand\u0001"}`
+	if string(result) != expected {
+		t.Errorf("Canonicalize() = %s, expected %s", result, expected)
+	}
+	same, err := Canonicalize(Must(Unmarshal([]byte(strings.Replace(expected, "1e+21", "1000000000000000000000", 1)))))
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if string(same) != expected {
+		t.Errorf("Canonicalize() = %s, expected %s", same, expected)
+	}
+	if _, err = Canonicalize(nil); err == nil {
+		t.Errorf("Canonicalize() expected error")
+	}
+}
+
+func ExampleCanonicalize() {
+	root := Must(Unmarshal([]byte(`{"b": 1.50, "a": [2e-3, 1E30], "c": "\u00e9"}`)))
+	result, err := Canonicalize(root)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", result)
+	// Output:
+	// {"a":[0.002,1e+30],"b":1.5,"c":"é"}
+}
@@ -3,31 +3,38 @@ package main
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/spyzhov/ajson"
+	"github.com/spyzhov/ajson/schema"
 )
 
 var version = "v0.4.2"
 
 func usage() {
 	text := ``
-	if inArgs("-h", "-help", "--help", "help") || len(os.Args) > 3 {
-		text = `Usage: ajson "jsonpath" ["input"]
+	if inArgs("-h", "-help", "--help", "help") || (len(arguments()) > 3 && !isSchema()) {
+		text = `Usage: ajson [--lines] [--pretty] "jsonpath" ["input"]
+       ajson schema [--pretty] ["input" ...]
   Read JSON and evaluate it with JSONPath.
+  Command "schema" infers JSON Schema from all JSON values of the inputs, used as samples.
 Argument:
   jsonpath   Valid JSONPath or evaluate string (Examples: "$..[?(@.price)]", "$..price", "avg($..price)")
   input      Path to the JSON file. Leave it blank to use STDIN.
+Options:
+  --lines    Read input as JSON Lines (NDJSON): evaluate each record and print results line by line.
+  --pretty   Print results in the pretty-printed form, with indentation.
 Examples:
   ajson "avg($..registered.age)" "https://randomuser.me/api/?results=5000"
   ajson "$.results.*.name" "https://randomuser.me/api/?results=10"
   curl -s "https://randomuser.me/api/?results=10" | ajson "$..coordinates"
-  ajson "$" example.json
-  echo "3" | ajson "2 * pi * $"`
+  ajson --pretty "$" example.json
+  echo "3" | ajson "2 * pi * $"
+  ajson schema --pretty first.json second.json
+  printf '{"level":"error"}\n{"level":"info"}' | ajson --lines "$.level"`
 	} else if inArgs("version", "-version", "--version") {
 		text = fmt.Sprintf(`ajson: Version %s
 Copyright (c) 2020 Pyzhov Stepan
@@ -44,49 +51,125 @@ There is NO WARRANTY, to the extent permitted by law.`, version)
 func main() {
 	log.SetFlags(0)
 	usage()
-	if len(os.Args) < 2 {
+	args := arguments()
+	if len(args) < 2 {
 		log.Fatalf("JSONPath was not set")
 	}
-	path := os.Args[1]
-	input := getInput()
+	options := ajson.EncoderOptions{
+		EscapeHTML:      true,
+		TrailingNewline: true,
+	}
+	if inArgs("--pretty", "-pretty") {
+		options.Indent = "  "
+	}
+	if isSchema() {
+		inferSchema(args[2:], ajson.NewEncoderWithOptions(os.Stdout, options))
+		return
+	}
+
+	path := args[1]
+	input := getInput(args)
 	defer func() {
 		_ = input.Close()
 	}()
-	data, err := ioutil.ReadAll(input)
 
-	if err != nil {
-		log.Fatalf("error reading source: %s", err)
+	decoder := ajson.NewDecoder(input)
+	if inArgs("--lines", "-lines") {
+		lines(decoder, ajson.NewEncoderWithOptions(os.Stdout, options), path)
+		return
 	}
-	var result *ajson.Node
 
-	root, err := ajson.Unmarshal(data)
+	root, err := decoder.Decode()
 	if err != nil {
 		log.Fatalf("error parsing JSON: %s", err)
 	}
+	if _, err = decoder.Decode(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("unexpected data after the JSON value on line %d", decoder.Line())
+		}
+		log.Fatalf("error parsing JSON: %s", err)
+	}
 
-	var nodes []*ajson.Node
-	nodes, err = root.JSONPath(path)
-	result = ajson.ArrayNode("", nodes)
+	result, err := evaluate(root, path)
 	if err != nil {
-		result, err = ajson.Eval(root, path)
+		log.Fatalf("error: %s", err)
+	}
+
+	options.KeepSource = options.Indent == ""
+	if err = ajson.NewEncoderWithOptions(os.Stdout, options).Encode(result); err != nil {
+		log.Fatalf("error preparing JSON: %s", err)
+	}
+}
+
+func lines(decoder *ajson.Decoder, encoder *ajson.Encoder, path string) {
+	for {
+		root, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error parsing JSON: %s", err)
+		}
+		result, err := evaluate(root, path)
+		if err != nil {
+			log.Fatalf("error on line %d: %s", decoder.Line(), err)
+		}
+		if err = encoder.Encode(result); err != nil {
+			log.Fatalf("error preparing JSON: %s", err)
+		}
 	}
+}
+
+// inferSchema reads all JSON values of the inputs and prints the JSON Schema, inferred from them
+func inferSchema(inputs []string, encoder *ajson.Encoder) {
+	if len(inputs) == 0 {
+		inputs = []string{""}
+	}
+	var samples []*ajson.Node
+	for _, name := range inputs {
+		input := open(name)
+		decoder := ajson.NewDecoder(input)
+		for {
+			root, err := decoder.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				log.Fatalf("error parsing JSON: %s", err)
+			}
+			samples = append(samples, root)
+		}
+		_ = input.Close()
+	}
+	result, err := schema.InferSchema(samples...)
 	if err != nil {
 		log.Fatalf("error: %s", err)
 	}
+	if err = encoder.Encode(result); err != nil {
+		log.Fatalf("error preparing JSON: %s", err)
+	}
+}
 
-	data, err = ajson.Marshal(result)
+func evaluate(root *ajson.Node, path string) (*ajson.Node, error) {
+	nodes, err := root.JSONPath(path)
 	if err != nil {
-		log.Fatalf("error preparing JSON: %s", err)
+		return ajson.Eval(root, path)
 	}
-	fmt.Printf("%s\n", data)
+	return ajson.ArrayNode("", nodes), nil
 }
 
-func getInput() io.ReadCloser {
-	if len(os.Args) < 3 {
+func getInput(args []string) io.ReadCloser {
+	if len(args) < 3 {
 		return os.Stdin
 	}
+	return open(args[2])
+}
 
-	input := os.Args[2]
+// open returns the content of the file or the URL, or STDIN if the input is empty
+func open(input string) io.ReadCloser {
+	if input == "" {
+		return os.Stdin
+	}
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
 		resp, err := http.DefaultClient.Get(input)
 		if err != nil {
@@ -104,6 +187,23 @@ func getInput() io.ReadCloser {
 	return file
 }
 
+// arguments returns command line arguments without options
+func arguments() []string {
+	result := make([]string, 0, len(os.Args))
+	for _, val := range os.Args {
+		if !strings.HasPrefix(val, "--") && val != "-lines" && val != "-pretty" {
+			result = append(result, val)
+		}
+	}
+	return result
+}
+
+// isSchema returns true for the "schema" command
+func isSchema() bool {
+	args := arguments()
+	return len(args) > 1 && args[1] == "schema"
+}
+
 func inArgs(value ...string) bool {
 	index := make(map[string]bool, len(value))
 	for _, val := range value {
@@ -22,32 +22,74 @@ const (
 // Unmarshal parses the JSON-encoded data and return the root node of struct.
 //
 // Doesn't calculate values, just type of stored value. It will store link to the data, on all life long.
+//
+// Syntax errors are returned as Error with the line, the column, the path of the parsed node,
+// the expectation and the snippet of the data.
 func Unmarshal(data []byte) (root *Node, err error) {
+	return unmarshal(data, nil, nil)
+}
+
+// UnmarshalLenient parses the JSON-encoded data same as Unmarshal, but doesn't stop on the syntax errors.
+//
+// After each error the wrong part of the data is skipped till the next comma or closing bracket,
+// missing or broken values are replaced with null placeholders, and unclosed containers are closed.
+// Returns the partial tree (nil if there is no value at all) and all found errors.
+//
+// Example:
+//
+//	root, errs := UnmarshalLenient([]byte(`{"a": 1, "b": tru, "c" 3, "d": [1, 2}`))
+//	// root: {"a":1,"b":null,"c":null,"d":[1,2]}
+//	// errs: 3 errors with line, column and path of each one
+func UnmarshalLenient(data []byte) (root *Node, errs []Error) {
+	root, _ = unmarshal(data, &errs, nil)
+	return
+}
+
+// unmarshal parses the data. If errs is set, syntax errors are collected into it, and the parsing is resumed.
+// If options are set, the relaxed syntax is parsed.
+func unmarshal(data []byte, errs *[]Error, options *ParseOptions) (root *Node, err error) {
 	buf := newBuffer(data)
 	var (
 		state   States
 		key     *string
 		current *Node
 	)
+	defer func() {
+		if err != nil {
+			err = parseError(err, buf, current)
+		}
+	}()
 
 	_, err = buf.first()
 	if err != nil {
-		return nil, buf.errorEOF()
+		err = buf.errorEOF()
+		if errs != nil {
+			*errs = append(*errs, parseError(err, buf, current).(Error))
+			err = nil
+		}
+		return nil, err
 	}
 
 	for {
-		state = buf.getState()
-		if state == __ {
-			return nil, buf.errorSymbol()
+		if options != nil {
+			state, err = relax(buf, options, &current, &key)
+		} else {
+			state = buf.getState()
 		}
-
-		if state >= GO {
+		if err != nil {
+			// the relaxed syntax is broken
+		} else if state == __ {
+			err = buf.errorSymbol()
+		} else if state >= GO {
 			// region Change State
 			switch buf.state {
 			case ST:
 				if current != nil && current.IsObject() && key == nil {
 					// Detected: Key
 					key, err = getString(buf)
+					if err != nil {
+						break
+					}
 					buf.state = CO
 				} else {
 					// Detected: String
@@ -55,7 +97,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 					if err != nil {
 						break
 					}
-					err = buf.string(quotes, false)
+					if err = buf.string(quotes, false); err != nil {
+						break
+					}
 					current.borders[1] = buf.index + 1
 					buf.state = OK
 					if current.parent != nil {
@@ -67,7 +111,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				if err != nil {
 					break
 				}
-				err = buf.numeric(false)
+				if err = buf.numeric(false); err != nil {
+					break
+				}
 				current.borders[1] = buf.index
 				buf.index -= 1
 				buf.state = OK
@@ -84,6 +130,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				} else {
 					err = buf.false()
 				}
+				if err != nil {
+					break
+				}
 				current.borders[1] = buf.index + 1
 				buf.state = OK
 				if current.parent != nil {
@@ -94,7 +143,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				if err != nil {
 					break
 				}
-				err = buf.null()
+				if err = buf.null(); err != nil {
+					break
+				}
 				current.borders[1] = buf.index + 1
 				buf.state = OK
 				if current.parent != nil {
@@ -108,6 +159,7 @@ func Unmarshal(data []byte) (root *Node, err error) {
 			case ec: /* empty } */
 				if key != nil {
 					err = buf.errorSymbol()
+					break
 				}
 				fallthrough
 			case cc: /* } */
@@ -138,9 +190,8 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				buf.state = AR
 			case cm: /* , */
 				if current == nil {
-					return nil, buf.errorSymbol()
-				}
-				if current.IsObject() {
+					err = buf.errorSymbol()
+				} else if current.IsObject() {
 					buf.state = KE
 				} else if current.IsArray() {
 					buf.state = VA
@@ -159,7 +210,15 @@ func Unmarshal(data []byte) (root *Node, err error) {
 			// endregion Action
 		}
 		if err != nil {
-			return
+			if errs == nil {
+				return nil, err
+			}
+			*errs = append(*errs, parseError(err, buf, current).(Error))
+			err = nil
+			if !resume(buf, &current, &key) {
+				break
+			}
+			continue
 		}
 		if buf.step() != nil {
 			break
@@ -170,12 +229,15 @@ func Unmarshal(data []byte) (root *Node, err error) {
 		}
 	}
 
+	if errs != nil {
+		return complete(buf, current, key, errs), nil
+	}
 	if current == nil || buf.state != OK {
-		err = buf.errorEOF()
+		err = errorEOFAt(buf.length)
 	} else {
 		root = current.root()
 		if !root.ready() {
-			err = buf.errorEOF()
+			err = errorEOFAt(buf.length)
 			root = nil
 		}
 	}
@@ -183,6 +245,217 @@ func Unmarshal(data []byte) (root *Node, err error) {
 	return
 }
 
+// resume skips the wrong part of the data after the syntax error, and replaces missing or broken values with
+// placeholders. Returns false, if the rest of the data can't be parsed.
+func resume(buf *buffer, current **Node, key **string) bool {
+	node := *current
+	if node == nil {
+		// there is no value yet: skip the symbol
+		buf.state = GO
+		if buf.step() != nil {
+			return false
+		}
+		_, err := buf.first()
+		return err == nil
+	}
+	if node.parent == nil && node.ready() {
+		// the rest of the data after the root value
+		return false
+	}
+	state := buf.state
+	if state < GO {
+		state = buf.last
+	}
+	if state >= ST && state <= U4 {
+		buf.index = skipString(buf.data, buf.index)
+	}
+	if !node.isContainer() {
+		// the value is broken
+		placeholder(node)
+		if node.parent == nil {
+			return false
+		}
+		node = node.parent
+		state = OK
+	}
+	node.mark()
+	if state == OK && buf.index < buf.length && missingComma(node, buf.data[buf.index]) {
+		*current = node
+		if node.IsObject() {
+			buf.state = KE
+		} else {
+			buf.state = VA
+		}
+		return true
+	}
+	start := buf.index
+	buf.index = skipValue(buf.data, buf.index)
+	if buf.index >= buf.length {
+		*current = node
+		buf.state = OK
+		return false
+	}
+	c := buf.data[buf.index]
+	if *key != nil && node.IsObject() || node.IsArray() && (state == AR || state == VA) && (c == coma || start != buf.index) {
+		// the value is missing
+		child, _ := newNode(node, buf, Null, key)
+		placeholder(child)
+	}
+	// closing bracket of the parent closes the node
+	for c == bracesR && !node.IsObject() || c == bracketR && !node.IsArray() {
+		node.borders[1] = buf.index
+		if node.parent == nil {
+			*current = node
+			return false
+		}
+		node = node.parent
+	}
+	*current = node
+	buf.state = OK
+	return true
+}
+
+// missingComma checks if the symbol starts the next element of the container, so only the comma is missing
+func missingComma(node *Node, c byte) bool {
+	if node.IsObject() {
+		return c == quotes
+	}
+	switch c {
+	case quotes, minus, bracketL, bracesL, 't', 'f', 'n':
+		return true
+	}
+	return c >= '0' && c <= '9'
+}
+
+// complete closes all unfinished nodes on the end of the data in the lenient mode, and returns the root node
+func complete(buf *buffer, current *Node, key *string, errs *[]Error) *Node {
+	if current == nil {
+		return nil
+	}
+	for node := current; node != nil; node = node.parent {
+		if node.isContainer() && !node.ready() {
+			if size := len(*errs); size == 0 || (*errs)[size-1].Type != UnexpectedEOF {
+				*errs = append(*errs, parseError(errorEOFAt(buf.length), buf, current).(Error))
+			}
+			break
+		}
+	}
+	if key != nil && current.IsObject() && !current.ready() {
+		child, _ := newNode(current, buf, Null, &key)
+		placeholder(child)
+	}
+	for node := current; node != nil; node = node.parent {
+		if node.isContainer() && !node.ready() {
+			node.borders[1] = buf.length
+			node.mark()
+		}
+	}
+	return current.root()
+}
+
+// placeholder turns the node into the null value, which is not bound to the data
+func placeholder(node *Node) {
+	node._type = Null
+	node.data = nil
+	node.borders = [2]int{}
+	node.mark()
+}
+
+// skipString returns the index after the end of the string, which contains the index, or the end of the line
+func skipString(data []byte, index int) int {
+	for index < len(data) {
+		switch data[index] {
+		case backslash:
+			index++
+		case quotes:
+			return index + 1
+		case skipN:
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+// skipValue returns the index of the next comma or closing bracket, which is not inside the skipped value
+func skipValue(data []byte, index int) int {
+	depth := 0
+	for index < len(data) {
+		switch data[index] {
+		case quotes:
+			index = skipString(data, index+1)
+			continue
+		case bracketL, bracesL:
+			depth++
+		case bracketR, bracesR:
+			if depth == 0 {
+				return index
+			}
+			depth--
+		case coma:
+			if depth == 0 {
+				return index
+			}
+		}
+		index++
+	}
+	return index
+}
+
+// parseError adds the position, the path of the current node and the expectation into the parsing error
+func parseError(err error, buf *buffer, current *Node) error {
+	path := "$"
+	if current != nil {
+		path = current.Path()
+	}
+	state := buf.state
+	if state < GO {
+		// the symbol was rejected, so the expectation is defined by the previous state
+		state = buf.last
+	}
+	container := Null
+	if current != nil && !current.ready() {
+		container = current.Type()
+	}
+	return errorContext(err, buf.data, path, expectation(state, container))
+}
+
+// expectation returns the description of the expected symbols in the state, the container is the type of the
+// unfinished node
+func expectation(state States, container NodeType) string {
+	switch state {
+	case GO, VA:
+		return "expected value"
+	case OK:
+		switch container {
+		case Object:
+			return "expected ',' or '}'"
+		case Array:
+			return "expected ',' or ']'"
+		}
+		return "expected end of data"
+	case OB:
+		return "expected object key or '}'"
+	case KE:
+		return "expected object key"
+	case CO:
+		return "expected ':' after object key"
+	case AR:
+		return "expected value or ']'"
+	case ST, ES, U1, U2, U3, U4:
+		return "expected valid string"
+	case MI, ZE, IN, DT, FR, E1, E2, E3:
+		return "expected valid number"
+	case T1, T2, T3:
+		return "expected 'true'"
+	case F1, F2, F3, F4:
+		return "expected 'false'"
+	case N1, N2, N3:
+		return "expected 'null'"
+	}
+	return ""
+}
+
 // UnmarshalSafe do the same thing as Unmarshal, but copy data to the local variable, to make it editable.
 func UnmarshalSafe(data []byte) (root *Node, err error) {
 	var safe []byte
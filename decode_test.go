@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -795,7 +796,7 @@ func ExampleMust_panic() {
 	root := Must(Unmarshal(data))
 	fmt.Printf("Object has %d inheritors inside", root.Size())
 	// Output:
-	// Unmarshal(): wrong symbol ']' at 1
+	// Unmarshal(): wrong symbol ']' at 1 on line 1, column 2 in $: expected object key or '}'
 }
 
 func TestUnmarshal_main(t *testing.T) {
@@ -2893,3 +2894,108 @@ line"]`)},
 		})
 	}
 }
+
+func TestUnmarshalLenient(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		errors   []string
+	}{
+		{name: "valid", data: `{"a": [1, 2]}`, expected: `{"a": [1, 2]}`},
+		{name: "broken values", data: `{"a": 1, "b": tru, "c" 3, "d": [1, 2}`, expected: `{"a":1,"b":null,"c":null,"d":[1,2]}`, errors: []string{"$['b']", "$", "$['d']"}},
+		{name: "wrong element", data: `[1, x, 3]`, expected: `[1,null,3]`, errors: []string{"$"}},
+		{name: "missing comma", data: "{\n  \"a\": 1\n  \"b\": [1 2]\n}", expected: `{"a":1,"b":[1,2]}`, errors: []string{"$", "$['b']"}},
+		{name: "trailing comma", data: `{"a": [1,], "b": 2,}`, expected: `{"a":[1],"b":2}`, errors: []string{"$['a']", "$"}},
+		{name: "missing element", data: `[,1]`, expected: `[null,1]`, errors: []string{"$"}},
+		{name: "missing value", data: `{"a":, "b"}`, expected: `{"a":null,"b":null}`, errors: []string{"$", "$"}},
+		{name: "wrong key", data: `{"a\x": 1, "b": 2}`, expected: `{"b":2}`, errors: []string{"$"}},
+		{name: "wrong string", data: `["a\x, b", 1]`, expected: `[null,1]`, errors: []string{"$[0]"}},
+		{name: "skipped value", data: `{"a" [1, {"b": 2}], "c": 3}`, expected: `{"a":null,"c":3}`, errors: []string{"$"}},
+		{name: "wrong bracket", data: `{"a": {"b": [1, }, "c": 2}`, expected: `{"a":{"b":[1]},"c":2}`, errors: []string{"$['a']['b']"}},
+		{name: "wrong root bracket", data: `[1}`, expected: `[1]`, errors: []string{"$"}},
+		{name: "unclosed", data: `{"a": [1, {"b": 2`, expected: `{"a":[1,{"b":2}]}`, errors: []string{"$['a'][1]"}},
+		{name: "unclosed key", data: `{"a"`, expected: `{"a":null}`, errors: []string{"$"}},
+		{name: "unclosed value", data: `[tru`, expected: `[null]`, errors: []string{"$[0]"}},
+		{name: "root value", data: `tru`, expected: `null`, errors: []string{"$"}},
+		{name: "rest of data", data: `{} x`, expected: `{}`, errors: []string{"$"}},
+		{name: "wrong data", data: `x`, errors: []string{"$"}},
+		{name: "empty", data: ``, errors: []string{"$"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, errs := UnmarshalLenient([]byte(test.data))
+			if test.expected == "" {
+				if root != nil {
+					t.Errorf("UnmarshalLenient() = %s, expected nil", root)
+				}
+			} else {
+				result, err := Marshal(root)
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				if string(result) != test.expected {
+					t.Errorf("UnmarshalLenient() = %s, expected %s", result, test.expected)
+				}
+			}
+			paths := make([]string, 0, len(errs))
+			for _, err := range errs {
+				paths = append(paths, err.Path)
+			}
+			if len(paths) != len(test.errors) || len(paths) != 0 && !reflect.DeepEqual(paths, test.errors) {
+				t.Errorf("UnmarshalLenient() errors = %v, expected at %v", errs, test.errors)
+			}
+		})
+	}
+}
+
+func TestUnmarshalLenient_mutations(t *testing.T) {
+	alphabet := []byte(`{}[],:"\ 1-.etx`)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		data := append([]byte{}, jsonExample...)
+		for j := random.Intn(4) + 1; j > 0; j-- {
+			position := random.Intn(len(data))
+			switch random.Intn(3) {
+			case 0:
+				data[position] = alphabet[random.Intn(len(alphabet))]
+			case 1:
+				data = append(data[:position], data[position+1:]...)
+			default:
+				data = append(data[:position], append([]byte{alphabet[random.Intn(len(alphabet))]}, data[position:]...)...)
+			}
+		}
+		root, errs := UnmarshalLenient(data)
+		if _, err := Unmarshal(data); (err == nil) != (len(errs) == 0) {
+			t.Fatalf("UnmarshalLenient(%s) errors = %v, Unmarshal() error = %v", data, errs, err)
+		}
+		if root == nil {
+			continue
+		}
+		result, err := Marshal(root)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v for %s", err, data)
+		}
+		if _, err = Unmarshal(result); err != nil {
+			t.Fatalf("UnmarshalLenient(%s) = %s is not valid: %v", data, result, err)
+		}
+	}
+}
+
+func ExampleUnmarshalLenient() {
+	root, errs := UnmarshalLenient([]byte(`{
+	"name": "example",
+	"size" 10,
+	"tags": ["a", "b",],
+}`))
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	result, _ := Marshal(root)
+	fmt.Printf("%s", result)
+	// Output:
+	// wrong symbol '1' at 30 on line 3, column 9 in $: expected ':' after object key
+	// wrong symbol ']' at 53 on line 4, column 20 in $['tags']: expected value
+	// wrong symbol '}' at 56 on line 5, column 1 in $: expected object key
+	// {"name":"example","size":null,"tags":["a","b"]}
+}
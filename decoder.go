@@ -0,0 +1,142 @@
+package ajson
+
+import (
+	"io"
+	"strconv"
+)
+
+// Decoder reads and decodes JSON values from an input stream.
+// Values could be concatenated or separated by whitespaces, so Decoder is suitable to read JSON Lines (NDJSON) as well.
+//
+// In contrast to Unmarshal, Decoder doesn't keep the whole input in memory:
+// it reads data in chunks and every scalar node receives its own copy of the source,
+// so Source, Marshal and JSONPath work as usual.
+// Container nodes (Array and Object) have no source of their own and are calculated from their children:
+// they are marked as dirty, Source returns nil for them and Marshal rebuilds them from the children.
+type Decoder struct {
+	tokenizer *Tokenizer
+}
+
+// builder is the Handler, which creates the nodes for the Decoder
+type builder struct {
+	root    *Node
+	current *Node
+	last    *Node
+	key     *string
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		tokenizer: NewTokenizer(r),
+	}
+}
+
+// Offset returns the count of bytes, which were read from the input stream.
+func (d *Decoder) Offset() int {
+	return d.tokenizer.Offset()
+}
+
+// Line returns the number of the current line in the input stream, starting from 1.
+func (d *Decoder) Line() int {
+	return d.tokenizer.Line()
+}
+
+// SkipLine discards the rest of the current line in the input stream.
+// It can be used to continue reading of JSON Lines after an error.
+func (d *Decoder) SkipLine() error {
+	return d.tokenizer.SkipLine()
+}
+
+// Decode reads the next JSON-encoded value from its input and returns the root node of it.
+//
+// Decode returns io.EOF if there are no more values in the input stream.
+func (d *Decoder) Decode() (root *Node, err error) {
+	b := new(builder)
+	if err = d.tokenizer.Tokenize(b); err != nil {
+		return nil, err
+	}
+	return b.root, nil
+}
+
+// BeginObject is the implementation of the Handler interface
+func (b *builder) BeginObject(int) error {
+	b.container(Object)
+	return nil
+}
+
+// EndObject is the implementation of the Handler interface
+func (b *builder) EndObject(int) error {
+	b.close()
+	return nil
+}
+
+// BeginArray is the implementation of the Handler interface
+func (b *builder) BeginArray(int) error {
+	b.container(Array)
+	return nil
+}
+
+// EndArray is the implementation of the Handler interface
+func (b *builder) EndArray(int) error {
+	b.close()
+	return nil
+}
+
+// Key is the implementation of the Handler interface
+func (b *builder) Key(key string, _ int) error {
+	b.key = &key
+	return nil
+}
+
+// Value is the implementation of the Handler interface, creates a node with a copy of the source
+func (b *builder) Value(_type NodeType, source []byte, _ int) error {
+	data := make([]byte, len(source))
+	copy(data, source)
+	b.attach(&Node{
+		data:    &data,
+		borders: [2]int{0, len(data)},
+		_type:   _type,
+	})
+	return nil
+}
+
+// container creates a new Array or Object node, without a source
+func (b *builder) container(_type NodeType) {
+	current := &Node{
+		_type:    _type,
+		children: make(map[string]*Node),
+		dirty:    true,
+	}
+	b.attach(current)
+	b.current = current
+}
+
+// close finishes current container node
+func (b *builder) close() {
+	if b.current != nil {
+		b.current = b.current.parent
+	}
+}
+
+// attach links the node to the current container, by the key or the next index
+func (b *builder) attach(node *Node) {
+	b.last = node
+	parent := b.current
+	if parent == nil {
+		if b.root == nil {
+			b.root = node
+		}
+		return
+	}
+	node.parent = parent
+	if parent.IsArray() {
+		size := len(parent.children)
+		node.index = &size
+		parent.children[strconv.Itoa(size)] = node
+	} else if b.key != nil {
+		node.key = b.key
+		parent.setChild(*b.key, node)
+		b.key = nil
+	}
+}
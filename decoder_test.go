@@ -0,0 +1,244 @@
+package ajson
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "null", input: `null`},
+		{name: "true", input: ` true `},
+		{name: "false", input: "false\n"},
+		{name: "numeric", input: `-1.123E-3456`},
+		{name: "numeric with spaces", input: " \r 123 \t\n"},
+		{name: "string", input: `"good \\\"cat\""`},
+		{name: "unicode", input: `"Фыв Фыв"`},
+		{name: "empty array", input: `[]`},
+		{name: "empty object", input: `{ }`},
+		{name: "array", input: ` [1,["1",[1,[1,2,3]]]]`},
+		{name: "object", input: `{"foo":{"bar":{"baz":{}}},"a":[null,true,false,1.5e2,"x"]}`},
+		{name: "spaces", input: `  {  "foo"  :  "bar"  , "baz"   :   [ 1 , 2 ]   }    `},
+		{name: "example", input: string(jsonExample)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := Unmarshal([]byte(test.input))
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			root, err := NewDecoder(iotest.OneByteReader(strings.NewReader(test.input))).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if root.Type() != expected.Type() {
+				t.Errorf("Decode() type = %v, expected %v", root.Type(), expected.Type())
+			}
+			if !root.isContainer() && !bytes.Equal(root.Source(), expected.Source()) {
+				t.Errorf("Decode() source = %s, expected %s", root.Source(), expected.Source())
+			}
+			value, err := root.Unpack()
+			if err != nil {
+				t.Fatalf("Unpack() error = %v", err)
+			}
+			evalue, err := expected.Unpack()
+			if err != nil {
+				t.Fatalf("Unpack() error = %v", err)
+			}
+			if !reflect.DeepEqual(value, evalue) {
+				t.Errorf("Decode() value = %v, expected %v", value, evalue)
+			}
+			if ok, err := root.Eq(expected); err != nil || !ok {
+				t.Errorf("Decode() Eq = %v (%v)", ok, err)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_corrupted(t *testing.T) {
+	tests := []string{
+		``,
+		` `,
+		`+1`,
+		`1+1`,
+		`.`,
+		`-`,
+		`"foo`,
+		"\"foo\nbar\"",
+		`nul`,
+		`tru`,
+		`trux`,
+		`[,]`,
+		`[1,]`,
+		`[[]`,
+		`]`,
+		`{,}`,
+		`{:}`,
+		`{"foo"}`,
+		`{"foo":}`,
+		`{"foo":bar}`,
+		`{"foo":"bar",}`,
+		`{[]}`,
+		`{"x"::1}`,
+		`{null:null}`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			root, err := NewDecoder(strings.NewReader(test)).Decode()
+			if err == nil {
+				t.Errorf("Decode() expected error, got %v", root)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_stream(t *testing.T) {
+	input := "{\"a\":1} [2] 3 4\n\"five\"null{}"
+	decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	expected := []string{`{"a":1}`, `[2]`, `3`, `4`, `"five"`, `null`, `{}`}
+	for _, value := range expected {
+		root, err := decoder.Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		result, err := Marshal(root)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if string(result) != value {
+			t.Errorf("Decode() = %s, expected %s", result, value)
+		}
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("Decode() expected io.EOF, got %v", err)
+	}
+	if decoder.Offset() != len(input) {
+		t.Errorf("Offset() = %d, expected %d", decoder.Offset(), len(input))
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	_, err := NewDecoder(strings.NewReader(`{"foo": [1, 2, x]}`)).Decode()
+	if err == nil {
+		t.Fatalf("Decode() expected error")
+	}
+	if e, ok := err.(Error); !ok || e.Type != WrongSymbol || e.Index != 15 || e.Char != 'x' ||
+		e.Column != 16 || e.Path != "$['foo']" || e.Expected != "expected value" {
+		t.Errorf("Decode() wrong error: %#v", err)
+	}
+	_, err = NewDecoder(strings.NewReader(`{"foo": [1, 2`)).Decode()
+	if e, ok := err.(Error); !ok || e.Type != UnexpectedEOF || e.Index != 13 {
+		t.Errorf("Decode() wrong error: %v", err)
+	}
+}
+
+func TestDecoder_Decode_source(t *testing.T) {
+	root, err := NewDecoder(strings.NewReader(`{"foo": [1, "bar"]}`)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	foo := root.MustKey("foo")
+	for _, node := range []*Node{root, foo} {
+		if node.Source() != nil {
+			t.Errorf("Source() = %s, expected nil for the container", node.Source())
+		}
+	}
+	if source := string(foo.MustIndex(1).Source()); source != `"bar"` {
+		t.Errorf("Source() = %s, expected %s", source, `"bar"`)
+	}
+	result, err := Marshal(root)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(result) != `{"foo":[1,"bar"]}` {
+		t.Errorf("Marshal() = %s", result)
+	}
+}
+
+func TestDecoder_Decode_lines(t *testing.T) {
+	input := "{\"id\": 1}\n{\"id\": 2,}\n\n{\"id\":\n3}\n[x]\n\"foo\nbar\"\n{\"id\": 5}"
+	decoder := NewDecoder(strings.NewReader(input))
+	type record struct {
+		id   float64
+		line int
+		err  string
+	}
+	expected := []record{
+		{id: 1, line: 1},
+		{line: 2, err: "wrong symbol '}' at 19 on line 2, column 10 in $: expected object key"},
+		{id: 3, line: 5},
+		{line: 6, err: "wrong symbol 'x' at 33 on line 6, column 2 in $: expected value or ']'"},
+		{line: 7, err: "wrong symbol '\n' at 40 on line 7, column 5 in $: expected valid string"},
+		{line: 8, err: "wrong symbol 'b' at 41 on line 8, column 1 in $: expected value"},
+		{id: 5, line: 9},
+	}
+	for _, value := range expected {
+		root, err := decoder.Decode()
+		if value.err != "" {
+			if err == nil || err.Error() != value.err {
+				t.Errorf("Decode() wrong error: %v, expected: %s", err, value.err)
+			}
+			if err = decoder.SkipLine(); err != nil {
+				t.Fatalf("SkipLine() error = %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if id := root.MustKey("id").MustNumeric(); id != value.id {
+			t.Errorf("Decode() wrong id: %v, expected: %v", id, value.id)
+		}
+		if decoder.Line() != value.line {
+			t.Errorf("Line() = %d, expected: %d", decoder.Line(), value.line)
+		}
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("Decode() expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoder_Decode_JSONPath(t *testing.T) {
+	root, err := NewDecoder(bytes.NewReader(jsonExample)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	result, err := root.JSONPath("$..book[?(@.price < 10)].title")
+	if err != nil {
+		t.Fatalf("JSONPath() error = %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("JSONPath() wrong result size: %d", len(result))
+	}
+	if string(result[0].Source()) != `"Sayings of the Century"` || string(result[1].Source()) != `"Moby Dick"` {
+		t.Errorf("JSONPath() wrong result: %v", result)
+	}
+	if result[1].Path() != "$['store']['book'][2]['title']" {
+		t.Errorf("Path() wrong result: %s", result[1].Path())
+	}
+}
+
+func ExampleDecoder() {
+	decoder := NewDecoder(strings.NewReader(`{"name": "Alice", "age": 30} {"name": "Bob", "age": 25}`))
+	for {
+		root, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %v\n", root.MustKey("name").MustString(), root.MustKey("age").MustNumeric())
+	}
+	// Output:
+	// Alice: 30
+	// Bob: 25
+}
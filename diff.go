@@ -0,0 +1,216 @@
+package ajson
+
+import (
+	"strings"
+)
+
+// ChangeType is the type of the Change, found by Diff
+type ChangeType int
+
+const (
+	// ChangeAdded means that the value exists only in the second node
+	ChangeAdded ChangeType = iota
+	// ChangeRemoved means that the value exists only in the first node
+	ChangeRemoved
+	// ChangeModified means that the values are different
+	ChangeModified
+)
+
+// Change is the single difference between two nodes.
+type Change struct {
+	Type ChangeType
+	// Path is the JSONPath of the changed value, e.g.: `$['store']['book'][0]`
+	Path string
+	// Pointer is the JSON Pointer of the changed value, e.g.: `/store/book/0`
+	Pointer string
+	// Old value, nil for ChangeAdded
+	Old *Node
+	// New value, nil for ChangeRemoved
+	New *Node
+}
+
+// DiffOptions are the options of the DiffWithOptions.
+type DiffOptions struct {
+	// ArrayKey is the expression, which identifies elements of arrays, e.g.: `@.id`.
+	// If it's set, elements of arrays are matched by the key instead of the index.
+	// Arrays without unique keys for all of their elements are compared by the index.
+	ArrayKey *Expression
+}
+
+// Diff returns all differences between two nodes: added, removed and modified values.
+// Scalar values are compared with Eq, so `1` and `1.0` are the same. Objects are compared by the keys, and arrays by the
+// index. Changes are sorted by keys and indexes.
+//
+// Path and Pointer of the change are taken from the new node, or from the old node if the value was removed.
+func Diff(a, b *Node) []Change {
+	return DiffWithOptions(a, b, DiffOptions{})
+}
+
+// DiffWithOptions returns all differences between two nodes, like Diff, with the custom options.
+func DiffWithOptions(a, b *Node, options DiffOptions) []Change {
+	result := make([]Change, 0)
+	diff(a, b, &options, &result)
+	return result
+}
+
+// String returns the name of the ChangeType
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// String returns the change in the human readable form, e.g.:
+//
+//	~ $['price']: 8.95 -> 9.95
+//	+ $['tags'][2]: "new"
+//	- $['author']: "Herman Melville"
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return "+ " + c.Path + ": " + diffValue(c.New)
+	case ChangeRemoved:
+		return "- " + c.Path + ": " + diffValue(c.Old)
+	}
+	return "~ " + c.Path + ": " + diffValue(c.Old) + " -> " + diffValue(c.New)
+}
+
+// FormatDiff returns all changes in the human readable form, one change per line.
+func FormatDiff(changes []Change) string {
+	var sb strings.Builder
+	for _, change := range changes {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func diffValue(node *Node) string {
+	value, err := Marshal(node)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(value)
+}
+
+func newChange(_type ChangeType, a, b *Node) Change {
+	node := b
+	if node == nil {
+		node = a
+	}
+	return Change{
+		Type:    _type,
+		Path:    node.Path(),
+		Pointer: node.Pointer(),
+		Old:     a,
+		New:     b,
+	}
+}
+
+func diff(a, b *Node, options *DiffOptions, result *[]Change) {
+	if a.Type() != b.Type() {
+		*result = append(*result, newChange(ChangeModified, a, b))
+		return
+	}
+	switch a.Type() {
+	case Object:
+		diffObject(a, b, options, result)
+	case Array:
+		if options.ArrayKey != nil {
+			left, lok := diffKeys(a, options.ArrayKey)
+			right, rok := diffKeys(b, options.ArrayKey)
+			if lok && rok {
+				diffKeyedArray(a, b, left, right, options, result)
+				return
+			}
+		}
+		diffArray(a, b, options, result)
+	default:
+		if ok, err := a.Eq(b); err != nil || !ok {
+			*result = append(*result, newChange(ChangeModified, a, b))
+		}
+	}
+}
+
+func diffObject(a, b *Node, options *DiffOptions, result *[]Change) {
+	left, right := a.Inheritors(), b.Inheritors()
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		switch {
+		case j == len(right) || (i < len(left) && left[i].Key() < right[j].Key()):
+			*result = append(*result, newChange(ChangeRemoved, left[i], nil))
+			i++
+		case i == len(left) || left[i].Key() > right[j].Key():
+			*result = append(*result, newChange(ChangeAdded, nil, right[j]))
+			j++
+		default:
+			diff(left[i], right[j], options, result)
+			i++
+			j++
+		}
+	}
+}
+
+func diffArray(a, b *Node, options *DiffOptions, result *[]Change) {
+	left, right := a.Inheritors(), b.Inheritors()
+	for i := 0; i < len(left) || i < len(right); i++ {
+		switch {
+		case i >= len(right):
+			*result = append(*result, newChange(ChangeRemoved, left[i], nil))
+		case i >= len(left):
+			*result = append(*result, newChange(ChangeAdded, nil, right[i]))
+		default:
+			diff(left[i], right[i], options, result)
+		}
+	}
+}
+
+// diffKeyedArray matches elements of arrays by their keys: removed and modified elements are in order of the first
+// array, added elements are in order of the second one
+func diffKeyedArray(a, b *Node, left, right []string, options *DiffOptions, result *[]Change) {
+	lelements, relements := a.Inheritors(), b.Inheritors()
+	index := make(map[string]*Node, len(right))
+	for i, key := range right {
+		index[key] = relements[i]
+	}
+	found := make(map[string]bool, len(left))
+	for i, key := range left {
+		found[key] = true
+		if element, ok := index[key]; ok {
+			diff(lelements[i], element, options, result)
+		} else {
+			*result = append(*result, newChange(ChangeRemoved, lelements[i], nil))
+		}
+	}
+	for i, key := range right {
+		if !found[key] {
+			*result = append(*result, newChange(ChangeAdded, nil, relements[i]))
+		}
+	}
+}
+
+// diffKeys calculates keys of all elements of the array, returns false if keys are not unique or can't be calculated
+func diffKeys(array *Node, expr *Expression) ([]string, bool) {
+	elements := array.Inheritors()
+	result := make([]string, len(elements))
+	unique := make(map[string]bool, len(elements))
+	for i, element := range elements {
+		value, err := expr.Evaluate(element)
+		if err != nil {
+			return nil, false
+		}
+		key, err := Marshal(value)
+		if err != nil || unique[string(key)] {
+			return nil, false
+		}
+		unique[string(key)] = true
+		result[i] = string(key)
+	}
+	return result, true
+}
@@ -0,0 +1,136 @@
+package ajson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{name: "equal", a: `{"a":[1,{"b":null}],"c":"d"}`, b: `{"c":"d","a":[1.0,{"b":null}]}`, expected: []string{}},
+		{name: "scalar", a: `1`, b: `2`, expected: []string{`~ $: 1 -> 2`}},
+		{name: "type", a: `{"a":1}`, b: `{"a":"1"}`, expected: []string{`~ $['a']: 1 -> "1"`}},
+		{name: "container type", a: `{"a":[1]}`, b: `{"a":{"0":1}}`, expected: []string{`~ $['a']: [1] -> {"0":1}`}},
+		{name: "added key", a: `{"b":1}`, b: `{"a":[true],"b":1,"c":null}`, expected: []string{`+ $['a']: [true]`, `+ $['c']: null`}},
+		{name: "removed key", a: `{"a":1,"b":2}`, b: `{"b":2}`, expected: []string{`- $['a']: 1`}},
+		{name: "nested", a: `{"a":{"b":{"c":1,"d":2}}}`, b: `{"a":{"b":{"c":3,"e":4}}}`, expected: []string{
+			`~ $['a']['b']['c']: 1 -> 3`,
+			`- $['a']['b']['d']: 2`,
+			`+ $['a']['b']['e']: 4`,
+		}},
+		{name: "array", a: `[1,2,3]`, b: `[1,5]`, expected: []string{`~ $[1]: 2 -> 5`, `- $[2]: 3`}},
+		{name: "big integer", a: `{"id":1234567890123456789}`, b: `{"id":1234567890123456788}`, expected: []string{`~ $['id']: 1234567890123456789 -> 1234567890123456788`}},
+		{name: "array append", a: `{"a":[]}`, b: `{"a":["x","y"]}`, expected: []string{`+ $['a'][0]: "x"`, `+ $['a'][1]: "y"`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := Diff(Must(Unmarshal([]byte(test.a))), Must(Unmarshal([]byte(test.b))))
+			if len(changes) != len(test.expected) {
+				t.Fatalf("Diff() = %v, expected %v", changes, test.expected)
+			}
+			for i, change := range changes {
+				if change.String() != test.expected[i] {
+					t.Errorf("Diff()[%d] = %s, expected %s", i, change, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiff_change(t *testing.T) {
+	a := Must(Unmarshal([]byte(`{"a/b":[1,2]}`)))
+	b := Must(Unmarshal([]byte(`{"a/b":[1]}`)))
+	changes := Diff(a, b)
+	if len(changes) != 1 {
+		t.Fatalf("Diff() = %v, expected 1 change", changes)
+	}
+	change := changes[0]
+	if change.Type != ChangeRemoved || change.Path != `$['a/b'][1]` || change.Pointer != `/a~1b/1` {
+		t.Errorf("Diff() = %s %s %s", change.Type, change.Path, change.Pointer)
+	}
+	if change.Old != a.MustKey("a/b").MustIndex(1) || change.New != nil {
+		t.Errorf("Diff() wrong nodes: %v, %v", change.Old, change.New)
+	}
+}
+
+func TestDiffWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{
+			name: "by key",
+			a:    `[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":3,"v":"c"}]`,
+			b:    `[{"id":3,"v":"c"},{"id":4,"v":"d"},{"id":1,"v":"x"}]`,
+			expected: []string{
+				`~ $[2]['v']: "a" -> "x"`,
+				`- $[1]: {"id":2,"v":"b"}`,
+				`+ $[1]: {"id":4,"v":"d"}`,
+			},
+		},
+		{
+			name:     "reordered",
+			a:        `{"list":[{"id":"a"},{"id":"b"}]}`,
+			b:        `{"list":[{"id":"b"},{"id":"a"}]}`,
+			expected: []string{},
+		},
+		{
+			name:     "not unique",
+			a:        `[{"id":1,"v":1},{"id":1,"v":2}]`,
+			b:        `[{"id":1,"v":2},{"id":1,"v":1}]`,
+			expected: []string{`~ $[0]['v']: 1 -> 2`, `~ $[1]['v']: 2 -> 1`},
+		},
+		{
+			name:     "scalars",
+			a:        `[1,2,3]`,
+			b:        `[3,1]`,
+			expected: []string{`~ $[0]: 1 -> 3`, `~ $[1]: 2 -> 1`, `- $[2]: 3`},
+		},
+	}
+	options := DiffOptions{ArrayKey: MustCompileExpression("@.id")}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := DiffWithOptions(Must(Unmarshal([]byte(test.a))), Must(Unmarshal([]byte(test.b))), options)
+			if len(changes) != len(test.expected) {
+				t.Fatalf("DiffWithOptions() = %v, expected %v", changes, test.expected)
+			}
+			for i, change := range changes {
+				if change.String() != test.expected[i] {
+					t.Errorf("DiffWithOptions()[%d] = %s, expected %s", i, change, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	changes := Diff(Must(Unmarshal([]byte(`{"a":1,"b":2}`))), Must(Unmarshal([]byte(`{"b":3,"c":4}`))))
+	expected := "- $['a']: 1\n~ $['b']: 2 -> 3\n+ $['c']: 4\n"
+	if result := FormatDiff(changes); result != expected {
+		t.Errorf("FormatDiff() = %q, expected %q", result, expected)
+	}
+	if result := FormatDiff(nil); result != "" {
+		t.Errorf("FormatDiff() = %q, expected empty string", result)
+	}
+}
+
+func ExampleDiff() {
+	a := Must(Unmarshal([]byte(`{"title": "Moby Dick", "price": 8.99, "tags": ["novel"]}`)))
+	b := Must(Unmarshal([]byte(`{"title": "Moby Dick", "price": 9.99, "tags": ["novel", "classic"], "isbn": "0-553-21311-3"}`)))
+	for _, change := range Diff(a, b) {
+		fmt.Printf("%s %s\n", change.Type, change.Pointer)
+	}
+	fmt.Print(FormatDiff(Diff(a, b)))
+	// Output:
+	// added /isbn
+	// modified /price
+	// added /tags/1
+	// + $['isbn']: "0-553-21311-3"
+	// ~ $['price']: 8.99 -> 9.99
+	// + $['tags'][1]: "classic"
+}
@@ -1,90 +1,168 @@
 package ajson
 
 import (
+	"bufio"
+	"bytes"
+	"math"
+	"sort"
 	"strconv"
 )
 
-// Marshal returns slice of bytes, marshaled from current value
+// MarshalOptions are the options of the MarshalWithOptions.
+type MarshalOptions struct {
+	// SortKeys sorts keys of all objects, instead of keeping the order of parsing or appending.
+	// Objects are rebuilt from their children, even if they were not changed.
+	SortKeys bool
+}
+
+// Marshal returns slice of bytes, marshaled from current value.
+// Keys of objects are in order of parsing or appending.
 func Marshal(node *Node) (result []byte, err error) {
-	result = make([]byte, 0)
-	var (
-		sValue string
-		bValue bool
-		nValue float64
-		oValue []byte
-	)
+	return MarshalWithOptions(node, MarshalOptions{})
+}
+
+// MarshalWithOptions returns slice of bytes, marshaled from current value with the custom options.
+//
+// Example:
+//
+//	result, err := MarshalWithOptions(node, MarshalOptions{SortKeys: true})
+func MarshalWithOptions(node *Node, options MarshalOptions) (result []byte, err error) {
+	buf := new(bytes.Buffer)
+	err = NewEncoderWithOptions(buf, EncoderOptions{
+		EscapeHTML: true,
+		KeepSource: true,
+		SortKeys:   options.SortKeys,
+	}).Encode(node)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeState writes the node into the stream
+type encodeState struct {
+	writer  *bufio.Writer
+	options *EncoderOptions
+	pretty  bool
+}
 
+func (s *encodeState) encode(node *Node, depth int) (err error) {
 	if node == nil {
-		return nil, errorUnparsed()
-	} else if node.dirty {
-		switch node._type {
-		case Null:
-			result = append(result, _null...)
-		case Numeric:
-			nValue, err = node.GetNumeric()
-			if err != nil {
-				return nil, err
+		return errorUnparsed()
+	}
+	if !node.dirty {
+		if !node.ready() {
+			return errorUnparsed()
+		}
+		if node.isContainer() {
+			if s.options.KeepSource && !s.options.SortKeys {
+				_, _ = s.writer.Write(node.Source())
+				return nil
+			}
+		} else if s.options.KeepSource || node._type != String {
+			_, _ = s.writer.Write(node.Source())
+			return nil
+		}
+	} else if s.splicing() && node.spliced() {
+		return s.splice(node, depth)
+	}
+
+	switch node._type {
+	case Null:
+		_, _ = s.writer.Write(_null)
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return err
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			// same as JSON.stringify: there is no such number in JSON
+			_, _ = s.writer.Write(_null)
+		} else {
+			_, _ = s.writer.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	case String:
+		value, err := node.GetString()
+		if err != nil {
+			return err
+		}
+		s.quote(value)
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return err
+		} else if value {
+			_, _ = s.writer.Write(_true)
+		} else {
+			_, _ = s.writer.Write(_false)
+		}
+	case Array:
+		_ = s.writer.WriteByte(bracketL)
+		for i := 0; i < len(node.children); i++ {
+			if i != 0 {
+				_ = s.writer.WriteByte(coma)
+			}
+			child, ok := node.children[strconv.Itoa(i)]
+			if !ok {
+				return errorRequest("wrong length of array")
 			}
-			result = append(result, strconv.FormatFloat(nValue, 'g', -1, 64)...)
-		case String:
-			sValue, err = node.GetString()
-			if err != nil {
-				return nil, err
+			s.newline(depth + 1)
+			if err = s.encode(child, depth+1); err != nil {
+				return err
 			}
-			result = append(result, quotes)
-			result = append(result, quoteString(sValue, true)...)
-			result = append(result, quotes)
-		case Bool:
-			bValue, err = node.GetBool()
-			if err != nil {
-				return nil, err
-			} else if bValue {
-				result = append(result, _true...)
-			} else {
-				result = append(result, _false...)
+		}
+		if len(node.children) != 0 {
+			s.newline(depth)
+		}
+		_ = s.writer.WriteByte(bracketR)
+	case Object:
+		_ = s.writer.WriteByte(bracesL)
+		keys := node.keys
+		if s.options.SortKeys {
+			keys = node.Keys()
+			sort.Strings(keys)
+		}
+		for i, key := range keys {
+			if i != 0 {
+				_ = s.writer.WriteByte(coma)
 			}
-		case Array:
-			result = append(result, bracketL)
-			for i := 0; i < len(node.children); i++ {
-				if i != 0 {
-					result = append(result, coma)
-				}
-				child, ok := node.children[strconv.Itoa(i)]
-				if !ok {
-					return nil, errorRequest("wrong length of array")
-				}
-				oValue, err = Marshal(child)
-				if err != nil {
-					return nil, err
-				}
-				result = append(result, oValue...)
+			s.newline(depth + 1)
+			s.quote(key)
+			_ = s.writer.WriteByte(colon)
+			if s.pretty {
+				_ = s.writer.WriteByte(skipS)
 			}
-			result = append(result, bracketR)
-		case Object:
-			result = append(result, bracesL)
-			bValue = false
-			for key, child := range node.children {
-				if bValue {
-					result = append(result, coma)
-				} else {
-					bValue = true
-				}
-				result = append(result, quotes)
-				result = append(result, quoteString(key, true)...)
-				result = append(result, quotes, colon)
-				oValue, err = Marshal(child)
-				if err != nil {
-					return nil, err
-				}
-				result = append(result, oValue...)
+			if err = s.encode(node.children[key], depth+1); err != nil {
+				return err
 			}
-			result = append(result, bracesR)
 		}
-	} else if node.ready() {
-		result = append(result, node.Source()...)
-	} else {
-		return nil, errorUnparsed()
+		if len(keys) != 0 {
+			s.newline(depth)
+		}
+		_ = s.writer.WriteByte(bracesR)
 	}
+	return nil
+}
+
+// splicing checks if the changes of nodes, parsed with the comments, should be spliced into their source
+func (s *encodeState) splicing() bool {
+	return s.options.KeepSource && !s.options.SortKeys && !s.pretty
+}
 
-	return
+func (s *encodeState) quote(value string) {
+	_ = s.writer.WriteByte(quotes)
+	_, _ = s.writer.Write(quoteString(value, s.options.EscapeHTML, s.options.ASCII))
+	_ = s.writer.WriteByte(quotes)
+}
+
+// newline starts the new line of the pretty-printed output
+func (s *encodeState) newline(depth int) {
+	if !s.pretty {
+		return
+	}
+	_ = s.writer.WriteByte(skipN)
+	_, _ = s.writer.WriteString(s.options.Prefix)
+	for i := 0; i < depth; i++ {
+		_, _ = s.writer.WriteString(s.options.Indent)
+	}
 }
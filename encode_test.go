@@ -194,3 +194,109 @@ func TestMarshal_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshal_Order(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     func() *Node
+		expected string
+	}{
+		{
+			name: "parsed",
+			node: func() *Node {
+				root := Must(Unmarshal([]byte(`{"z":1,"a":2,"m":{"y":3,"b":4}}`)))
+				_ = root.MustKey("m").MustKey("y").SetNumeric(5)
+				return root
+			},
+			expected: `{"z":1,"a":2,"m":{"y":5,"b":4}}`,
+		},
+		{
+			name: "appended",
+			node: func() *Node {
+				root := Must(Unmarshal([]byte(`{"z":1,"a":2}`)))
+				_ = root.AppendObject("c", NullNode(""))
+				_ = root.AppendObject("b", NullNode(""))
+				return root
+			},
+			expected: `{"z":1,"a":2,"c":null,"b":null}`,
+		},
+		{
+			name: "replaced",
+			node: func() *Node {
+				root := Must(Unmarshal([]byte(`{"z":1,"a":2,"m":3}`)))
+				_ = root.AppendObject("a", StringNode("", "x"))
+				return root
+			},
+			expected: `{"z":1,"a":"x","m":3}`,
+		},
+		{
+			name: "deleted",
+			node: func() *Node {
+				root := Must(Unmarshal([]byte(`{"z":1,"a":2,"m":3}`)))
+				_ = root.DeleteKey("a")
+				_ = root.AppendObject("a", NumericNode("", 4))
+				return root
+			},
+			expected: `{"z":1,"m":3,"a":4}`,
+		},
+		{
+			name: "constructed",
+			node: func() *Node {
+				return ObjectNode("", map[string]*Node{"z": NumericNode("", 1), "a": NumericNode("", 2), "m": NumericNode("", 3)})
+			},
+			expected: `{"a":2,"m":3,"z":1}`,
+		},
+		{
+			name: "cloned",
+			node: func() *Node {
+				root := Must(Unmarshal([]byte(`{"z":1,"a":2,"m":3}`))).Clone()
+				_ = root.AppendObject("b", NullNode(""))
+				return root
+			},
+			expected: `{"z":1,"a":2,"m":3,"b":null}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				result, err := Marshal(test.node())
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				if string(result) != test.expected {
+					t.Fatalf("Marshal() = %s, expected %s", result, test.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalWithOptions(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"z": 1, "a": [{"d": true, "c": null}], "m": {}}`)))
+	result, err := MarshalWithOptions(root, MarshalOptions{SortKeys: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() error = %v", err)
+	}
+	if expected := `{"a":[{"c":null,"d":true}],"m":{},"z":1}`; string(result) != expected {
+		t.Errorf("MarshalWithOptions() = %s, expected %s", result, expected)
+	}
+	if result, err = MarshalWithOptions(root, MarshalOptions{}); err != nil {
+		t.Fatalf("MarshalWithOptions() error = %v", err)
+	} else if string(result) != string(root.Source()) {
+		t.Errorf("MarshalWithOptions() = %s, expected %s", result, root.Source())
+	}
+	if _, err = MarshalWithOptions(nil, MarshalOptions{SortKeys: true}); err == nil {
+		t.Errorf("MarshalWithOptions() expected error")
+	}
+}
+
+func ExampleMarshalWithOptions() {
+	root := Must(Unmarshal([]byte(`{"name": "Alice", "age": 30, "address": {"zip": "75001", "city": "Paris"}}`)))
+	result, err := MarshalWithOptions(root, MarshalOptions{SortKeys: true})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", result)
+	// Output:
+	// {"address":{"city":"Paris","zip":"75001"},"age":30,"name":"Alice"}
+}
@@ -0,0 +1,80 @@
+package ajson
+
+import (
+	"bufio"
+	"io"
+)
+
+// EncoderOptions are the options of the Encoder.
+type EncoderOptions struct {
+	// Prefix and Indent turn on the pretty-printed output: each element of an Array or an Object begins on a new line,
+	// which starts with Prefix followed by one or more copies of Indent according to the nesting.
+	Prefix string
+	Indent string
+	// EscapeHTML escapes characters <, > and & in strings.
+	EscapeHTML bool
+	// ASCII escapes all non-ASCII characters in strings as \uXXXX.
+	ASCII bool
+	// TrailingNewline adds the newline character after each value.
+	TrailingNewline bool
+	// KeepSource writes unchanged nodes from their Source as is. Otherwise, all nodes are formatted with current options.
+	// Changed containers, parsed with the KeepComments option, are spliced into their source, if the output is not
+	// pretty-printed and keys are not sorted.
+	KeepSource bool
+	// SortKeys sorts keys of all objects, instead of keeping the order of parsing or appending.
+	SortKeys bool
+}
+
+// Encoder writes JSON values to an output stream.
+type Encoder struct {
+	writer  io.Writer
+	options EncoderOptions
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+// Each value is written in the compact form on its own line, so Encoder is suitable to write JSON Lines (NDJSON).
+func NewEncoder(w io.Writer) *Encoder {
+	return NewEncoderWithOptions(w, EncoderOptions{
+		EscapeHTML:      true,
+		TrailingNewline: true,
+	})
+}
+
+// NewEncoderWithOptions returns a new Encoder that writes to w with the custom options.
+//
+// Example:
+//
+//	encoder := NewEncoderWithOptions(os.Stdout, EncoderOptions{Indent: "  ", TrailingNewline: true})
+func NewEncoderWithOptions(w io.Writer, options EncoderOptions) *Encoder {
+	return &Encoder{
+		writer:  w,
+		options: options,
+	}
+}
+
+// Encode writes the JSON encoding of the node to the stream.
+func (e *Encoder) Encode(node *Node) error {
+	state := &encodeState{
+		writer:  bufio.NewWriter(e.writer),
+		options: &e.options,
+		pretty:  e.options.Prefix != "" || e.options.Indent != "",
+	}
+	var layout *trivia
+	if node != nil && node.parent == nil && node.trivia != nil && node.trivia.parent == nil && state.splicing() {
+		// comments before and after the root, parsed with the KeepComments option
+		layout = node.trivia
+	}
+	if layout != nil {
+		_, _ = state.writer.Write((*layout.data)[layout.start:layout.value[0]])
+	}
+	if err := state.encode(node, 0); err != nil {
+		return err
+	}
+	if layout != nil {
+		_, _ = state.writer.Write((*layout.data)[layout.value[1]:layout.end])
+	}
+	if e.options.TrailingNewline {
+		_ = state.writer.WriteByte(skipN)
+	}
+	return state.writer.Flush()
+}
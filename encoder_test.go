@@ -0,0 +1,148 @@
+package ajson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *Node
+		expected string
+	}{
+		{name: "null", node: NullNode(""), expected: "null\n"},
+		{name: "numeric", node: NumericNode("", 1.5), expected: "1.5\n"},
+		{name: "string", node: StringNode("", "foo\nbar"), expected: "\"foo\\nbar\"\n"},
+		{name: "source", node: Must(Unmarshal([]byte("{\n\t\"foo\" : [ 1, \"b a r\" ],\r\n\t\"x\\\" y\": \"\\\\\" \n}"))), expected: "{\"foo\":[1,\"b a r\"],\"x\\\" y\":\"\\\\\"}\n"},
+		{name: "array", node: ArrayNode("", []*Node{NullNode(""), BoolNode("", true)}), expected: "[null,true]\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := NewEncoder(buf).Encode(test.node); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("Encode() = %q, expected %q", buf.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestEncoderOptions(t *testing.T) {
+	source := Must(Unmarshal([]byte("{\"b\": [1, 2.50, {}], \"a\": \"<\u00e9>\", \"c\": {\"d\": []}}")))
+	tests := []struct {
+		name     string
+		node     *Node
+		options  EncoderOptions
+		expected string
+	}{
+		{name: "default", node: source, options: EncoderOptions{}, expected: `{"b":[1,2.50,{}],"a":"<é>","c":{"d":[]}}`},
+		{name: "keep source", node: source, options: EncoderOptions{KeepSource: true}, expected: `{"b": [1, 2.50, {}], "a": "<é>", "c": {"d": []}}`},
+		{name: "escape HTML", node: source, options: EncoderOptions{EscapeHTML: true}, expected: `{"b":[1,2.50,{}],"a":"\u003cé\u003e","c":{"d":[]}}`},
+		{name: "ASCII", node: StringNode("", "é ж 😀"), options: EncoderOptions{ASCII: true}, expected: `"\u00e9 \u0436 \ud83d\ude00"`},
+		{name: "sort keys", node: source, options: EncoderOptions{SortKeys: true}, expected: `{"a":"<é>","b":[1,2.50,{}],"c":{"d":[]}}`},
+		{name: "sort keys with source", node: source, options: EncoderOptions{SortKeys: true, KeepSource: true}, expected: `{"a":"<é>","b":[1,2.50,{}],"c":{"d":[]}}`},
+		{name: "escaped source", node: Must(Unmarshal([]byte(`"\u00e9"`))), options: EncoderOptions{}, expected: `"é"`},
+		{name: "escaped source kept", node: Must(Unmarshal([]byte(`"\u00e9"`))), options: EncoderOptions{KeepSource: true}, expected: `"\u00e9"`},
+		{name: "trailing newline", node: NullNode(""), options: EncoderOptions{TrailingNewline: true}, expected: "null\n"},
+		{
+			name:     "indent",
+			node:     source,
+			options:  EncoderOptions{Indent: "\t"},
+			expected: "{\n\t\"b\": [\n\t\t1,\n\t\t2.50,\n\t\t{}\n\t],\n\t\"a\": \"<é>\",\n\t\"c\": {\n\t\t\"d\": []\n\t}\n}",
+		},
+		{
+			name:     "prefix",
+			node:     ArrayNode("", []*Node{NumericNode("", 1), StringNode("", "x")}),
+			options:  EncoderOptions{Prefix: "> ", Indent: "  ", TrailingNewline: true},
+			expected: "[\n>   1,\n>   \"x\"\n> ]\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := NewEncoderWithOptions(buf, test.options).Encode(test.node); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("Encode() = %q, expected %q", buf.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_error(t *testing.T) {
+	if err := NewEncoder(new(bytes.Buffer)).Encode(nil); err == nil {
+		t.Errorf("Encode() expected error for nil node")
+	}
+	if err := NewEncoder(failWriter{}).Encode(NullNode("")); err == nil {
+		t.Errorf("Encode() expected error from writer")
+	}
+}
+
+func TestEncoder_lines(t *testing.T) {
+	input := "{\"id\": 1}\n{\"id\": 2, \"tags\": [\"a\", \"b\"]}\n\n[3]\n"
+	decoder := NewDecoder(strings.NewReader(input))
+	buf := new(bytes.Buffer)
+	encoder := NewEncoder(buf)
+	for {
+		root, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if err = encoder.Encode(root); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	expected := "{\"id\":1}\n{\"id\":2,\"tags\":[\"a\",\"b\"]}\n[3]\n"
+	if buf.String() != expected && buf.String() != strings.Replace(expected, `"id":2,"tags":["a","b"]`, `"tags":["a","b"],"id":2`, 1) {
+		t.Errorf("Encode() = %q, expected %q", buf.String(), expected)
+	}
+}
+
+func ExampleEncoder() {
+	encoder := NewEncoder(os.Stdout)
+	for i := 1; i <= 3; i++ {
+		err := encoder.Encode(ArrayNode("", []*Node{NumericNode("", float64(i)), StringNode("", fmt.Sprintf("line %d", i))}))
+		if err != nil {
+			panic(err)
+		}
+	}
+	// Output:
+	// [1,"line 1"]
+	// [2,"line 2"]
+	// [3,"line 3"]
+}
+
+func ExampleNewEncoderWithOptions() {
+	root := Must(Unmarshal([]byte(`{"name": "Alice", "tags": ["a", "b"], "address": {}}`)))
+	encoder := NewEncoderWithOptions(os.Stdout, EncoderOptions{Indent: "  ", TrailingNewline: true})
+	if err := encoder.Encode(root); err != nil {
+		panic(err)
+	}
+	// Output:
+	// {
+	//   "name": "Alice",
+	//   "tags": [
+	//     "a",
+	//     "b"
+	//   ],
+	//   "address": {}
+	// }
+}
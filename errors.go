@@ -1,13 +1,44 @@
 package ajson
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 // Error is common struct to provide internal errors
 type Error struct {
 	Type    ErrorType
 	Index   int
+	Line    int
+	Column  int
 	Char    byte
 	Message string
+	// Path is the JSONPath of the node, which was parsed when the error occurred
+	Path string
+	// Expected describes what the parser expected to find, e.g.: "expected ':' after object key"
+	Expected string
+	// Snippet is the line of the source data with the caret under the error position
+	Snippet string
+}
+
+// PathError is the error of the JSONPath command, which failed to compile or to evaluate.
+//
+// PathError wraps the cause, so it can be checked with errors.Is and errors.As.
+type PathError struct {
+	// Path is the source of the JSONPath
+	Path string
+	// Segment is the index of the failed command in the parsed path
+	Segment int
+	// Command is the text of the failed command, e.g.: `?(@.price > 10)`
+	Command string
+	// Offset is the offset of the command text in the path, or -1 if it is unknown
+	Offset int
+	// Node is the Path() of the node, on which the command failed; empty if the command failed to compile
+	Node string
+	// Err is the cause of the error
+	Err error
 }
 
 // ErrorType is container for reflection type of error
@@ -42,6 +73,10 @@ func errorEOF(b *buffer) error {
 	return Error{Type: UnexpectedEOF, Index: b.index}
 }
 
+func errorEOFAt(index int) error {
+	return Error{Type: UnexpectedEOF, Index: index}
+}
+
 func errorType() error {
 	return Error{Type: WrongType}
 }
@@ -54,13 +89,112 @@ func errorRequest(format string, args ...interface{}) error {
 	return Error{Type: WrongRequest, Message: fmt.Sprintf(format, args...)}
 }
 
+// snippetWidth is the maximum count of bytes shown on each side of the error position in the snippet
+const snippetWidth = 40
+
+// errorContext fills the position of the parsing error in the data, the path of the parsed node and the expectation
+func errorContext(err error, data []byte, path, expected string) error {
+	result, ok := err.(Error)
+	if !ok {
+		return err
+	}
+	result.Line, result.Column, result.Snippet = position(data, result.Index)
+	result.Path = path
+	if result.Expected == "" {
+		result.Expected = expected
+	}
+	return result
+}
+
+// position returns the line and the column (both starting from 1) of the index in the data,
+// and the snippet of the line with the caret under the index
+func position(data []byte, index int) (line, column int, snippet string) {
+	if index > len(data) {
+		index = len(data)
+	} else if index < 0 {
+		index = 0
+	}
+	start := bytes.LastIndexByte(data[:index], '\n') + 1
+	end := bytes.IndexByte(data[index:], '\n')
+	if end == -1 {
+		end = len(data)
+	} else {
+		end += index
+	}
+	line = bytes.Count(data[:start], []byte{'\n'}) + 1
+	column = utf8.RuneCount(data[start:index]) + 1
+
+	var prefix, suffix string
+	if index-start > snippetWidth {
+		start = index - snippetWidth
+		for start < index && !utf8.RuneStart(data[start]) {
+			start++
+		}
+		prefix = "..."
+	}
+	if end-index > snippetWidth {
+		end = index + snippetWidth
+		for end > index && !utf8.RuneStart(data[end]) {
+			end--
+		}
+		suffix = "..."
+	}
+	caret := []byte(strings.Repeat(" ", len(prefix)))
+	for _, c := range string(data[start:index]) {
+		if c == '\t' {
+			caret = append(caret, '\t')
+		} else {
+			caret = append(caret, ' ')
+		}
+	}
+	snippet = prefix + strings.TrimRight(string(data[start:end]), "\r") + suffix + "\n" + string(caret) + "^"
+	return
+}
+
+// context returns the description of the error position, if it is known
+func (err Error) context() (result string) {
+	if err.Line != 0 {
+		result = fmt.Sprintf(" on line %d", err.Line)
+		if err.Column != 0 {
+			result += fmt.Sprintf(", column %d", err.Column)
+		}
+	}
+	if err.Path != "" {
+		result += " in " + err.Path
+	}
+	if err.Expected != "" {
+		result += ": " + err.Expected
+	}
+	return result
+}
+
+// Error interface implementation
+func (err *PathError) Error() string {
+	result := fmt.Sprintf("JSONPath segment %d '%s'", err.Segment, err.Command)
+	if err.Offset >= 0 {
+		result += fmt.Sprintf(" at %d", err.Offset)
+	}
+	if err.Path != "" {
+		result += fmt.Sprintf(" of '%s'", err.Path)
+	}
+	if err.Node != "" {
+		result += " on " + err.Node
+	}
+	return result + ": " + err.Err.Error()
+}
+
+// Unwrap returns the cause of the error
+func (err *PathError) Unwrap() error {
+	return err.Err
+}
+
 // Error interface implementation
 func (err Error) Error() string {
 	switch err.Type {
 	case WrongSymbol:
-		return fmt.Sprintf("wrong symbol '%s' at %d", []byte{err.Char}, err.Index)
+		return fmt.Sprintf("wrong symbol '%s' at %d", []byte{err.Char}, err.Index) + err.context()
 	case UnexpectedEOF:
-		return "unexpected end of file"
+		return fmt.Sprintf("unexpected end of file at %d", err.Index) + err.context()
 	case WrongType:
 		return "wrong type of Node"
 	case Unparsed:
@@ -1,15 +1,27 @@
 package ajson
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestError_Error(t *testing.T) {
 	tests := []struct {
-		name    string
-		_type   ErrorType
-		message string
+		name     string
+		_type    ErrorType
+		line     int
+		column   int
+		path     string
+		expected string
+		message  string
 	}{
 		{name: "WrongSymbol", _type: WrongSymbol, message: "wrong symbol 'S' at 10"},
-		{name: "UnexpectedEOF", _type: UnexpectedEOF, message: "unexpected end of file"},
+		{name: "WrongSymbol on line", _type: WrongSymbol, line: 2, message: "wrong symbol 'S' at 10 on line 2"},
+		{name: "WrongSymbol with context", _type: WrongSymbol, line: 2, column: 5, path: "$['a']", expected: "expected value", message: "wrong symbol 'S' at 10 on line 2, column 5 in $['a']: expected value"},
+		{name: "UnexpectedEOF", _type: UnexpectedEOF, message: "unexpected end of file at 10"},
+		{name: "UnexpectedEOF on line", _type: UnexpectedEOF, line: 3, message: "unexpected end of file at 10 on line 3"},
+		{name: "UnexpectedEOF with context", _type: UnexpectedEOF, line: 3, column: 1, path: "$", expected: "expected ',' or ']'", message: "unexpected end of file at 10 on line 3, column 1 in $: expected ',' or ']'"},
 		{name: "WrongType", _type: WrongType, message: "wrong type of Node"},
 		{name: "WrongRequest", _type: WrongRequest, message: "wrong request: example error"},
 		{name: "unknown", _type: -666, message: "unknown error: 'S' at 10"},
@@ -17,10 +29,14 @@ func TestError_Error(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			result := &Error{
-				Type:    test._type,
-				Index:   10,
-				Char:    'S',
-				Message: "example error",
+				Type:     test._type,
+				Index:    10,
+				Line:     test.line,
+				Column:   test.column,
+				Char:     'S',
+				Message:  "example error",
+				Path:     test.path,
+				Expected: test.expected,
 			}
 			if result.Error() != test.message {
 				t.Errorf("Wrong error message: %s", result.Error())
@@ -28,3 +44,124 @@ func TestError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestPathError_Error(t *testing.T) {
+	cause := errorRequest("division by zero")
+	tests := []struct {
+		name    string
+		err     *PathError
+		message string
+	}{
+		{
+			name:    "full",
+			err:     &PathError{Path: "$.a[?(@.b / 0)]", Segment: 2, Command: "?(@.b / 0)", Offset: 4, Node: "$['a'][1]", Err: cause},
+			message: "JSONPath segment 2 '?(@.b / 0)' at 4 of '$.a[?(@.b / 0)]' on $['a'][1]: wrong request: division by zero",
+		},
+		{
+			name:    "compilation",
+			err:     &PathError{Path: "$[?(@.b ~ 0)]", Segment: 1, Command: "?(@.b ~ 0)", Offset: 2, Err: cause},
+			message: "JSONPath segment 1 '?(@.b ~ 0)' at 2 of '$[?(@.b ~ 0)]': wrong request: division by zero",
+		},
+		{
+			name:    "unknown path",
+			err:     &PathError{Segment: 1, Command: "1:2:0", Offset: -1, Node: "$", Err: cause},
+			message: "JSONPath segment 1 '1:2:0' on $: wrong request: division by zero",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.err.Error() != test.message {
+				t.Errorf("Wrong error message: %s", test.err.Error())
+			}
+			if test.err.Unwrap() != cause {
+				t.Errorf("Unwrap() = %v, expected %v", test.err.Unwrap(), cause)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_errorContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		_type    ErrorType
+		index    int
+		line     int
+		column   int
+		path     string
+		expected string
+		snippet  string
+	}{
+		{name: "empty", data: ``, _type: UnexpectedEOF, index: 0, line: 1, column: 1, path: "$", expected: "expected value", snippet: "\n^"},
+		{name: "object start", data: `{]`, _type: WrongSymbol, index: 1, line: 1, column: 2, path: "$", expected: "expected object key or '}'", snippet: "{]\n ^"},
+		{name: "colon", data: `{"a" 1}`, _type: WrongSymbol, index: 5, line: 1, column: 6, path: "$", expected: "expected ':' after object key", snippet: "{\"a\" 1}\n     ^"},
+		{name: "trailing comma", data: `{"a":1,}`, _type: WrongSymbol, index: 7, line: 1, column: 8, path: "$", expected: "expected object key", snippet: "{\"a\":1,}\n       ^"},
+		{name: "array", data: "{\n\t\"a\": [1, 2}\n}", _type: WrongSymbol, index: 13, line: 2, column: 12, path: "$['a']", expected: "expected ',' or ']'", snippet: "\t\"a\": [1, 2}\n\t          ^"},
+		{name: "crlf", data: "[\r\n  1,\r\n  x\r\n]", _type: WrongSymbol, index: 11, line: 3, column: 3, path: "$", expected: "expected value", snippet: "  x\n  ^"},
+		{name: "literal", data: `{"a": tru}`, _type: WrongSymbol, index: 9, line: 1, column: 10, path: "$['a']", expected: "expected 'true'", snippet: "{\"a\": tru}\n         ^"},
+		{name: "number", data: `[{"a": -}]`, _type: WrongSymbol, index: 8, line: 1, column: 9, path: "$[0]['a']", expected: "expected valid number", snippet: "[{\"a\": -}]\n        ^"},
+		{name: "escape", data: `{"a": ["\x"]}`, _type: WrongSymbol, index: 9, line: 1, column: 10, path: "$['a'][0]", expected: "expected valid string", snippet: "{\"a\": [\"\\x\"]}\n         ^"},
+		{name: "unterminated string", data: `{"a": "b`, _type: UnexpectedEOF, index: 8, line: 1, column: 9, path: "$['a']", expected: "expected valid string", snippet: "{\"a\": \"b\n        ^"},
+		{name: "unterminated array", data: `[1`, _type: UnexpectedEOF, index: 2, line: 1, column: 3, path: "$", expected: "expected ',' or ']'", snippet: "[1\n  ^"},
+		{name: "end of data", data: `{} x`, _type: WrongSymbol, index: 3, line: 1, column: 4, path: "$", expected: "expected end of data", snippet: "{} x\n   ^"},
+		{name: "unicode", data: `["ü", x]`, _type: WrongSymbol, index: 7, line: 1, column: 7, path: "$", expected: "expected value", snippet: "[\"ü\", x]\n      ^"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(test.data))
+			result, ok := err.(Error)
+			if !ok {
+				t.Fatalf("Unmarshal() error = %v, expected Error", err)
+			}
+			if result.Type != test._type {
+				t.Errorf("Type = %v, expected %v", result.Type, test._type)
+			}
+			if result.Index != test.index || result.Line != test.line || result.Column != test.column {
+				t.Errorf("position = %d (%d:%d), expected %d (%d:%d)", result.Index, result.Line, result.Column, test.index, test.line, test.column)
+			}
+			if result.Path != test.path {
+				t.Errorf("Path = %s, expected %s", result.Path, test.path)
+			}
+			if result.Expected != test.expected {
+				t.Errorf("Expected = %s, expected %s", result.Expected, test.expected)
+			}
+			if result.Snippet != test.snippet {
+				t.Errorf("Snippet = \n%s\nexpected\n%s", result.Snippet, test.snippet)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_errorSnippet(t *testing.T) {
+	data := `{"value": "` + strings.Repeat("a", 100) + `" "next": "` + strings.Repeat("b", 100) + `"}`
+	_, err := Unmarshal([]byte(data))
+	result, ok := err.(Error)
+	if !ok {
+		t.Fatalf("Unmarshal() error = %v, expected Error", err)
+	}
+	lines := strings.Split(result.Snippet, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Snippet = %s, expected 2 lines", result.Snippet)
+	}
+	if !strings.HasPrefix(lines[0], "...") || !strings.HasSuffix(lines[0], "...") {
+		t.Errorf("Snippet = %s, expected to be cut", lines[0])
+	}
+	if caret := strings.Index(lines[1], "^"); caret < 0 || lines[0][caret] != '"' || lines[0][caret-1] != ' ' {
+		t.Errorf("Snippet = \n%s\ncaret is in the wrong position", result.Snippet)
+	}
+}
+
+func ExampleError_snippet() {
+	_, err := Unmarshal([]byte(`{
+	"name": "example",
+	"size" 10
+}`))
+	if result, ok := err.(Error); ok {
+		fmt.Println(result.Error())
+		fmt.Println(strings.Replace(result.Snippet, "\t", "    ", -1))
+	}
+	// Output:
+	// wrong symbol '1' at 30 on line 3, column 9 in $: expected ':' after object key
+	//     "size" 10
+	//            ^
+}
@@ -0,0 +1,287 @@
+package ajson
+
+import (
+	"errors"
+	"strings"
+)
+
+// Expression is the compiled script expression, like `@.price * 2 > $.expensive`.
+//
+// Expression is the AST of the formula: literals, constants, compiled JSONPath, operations and function calls.
+// Functions, operations and constants are bound on the compilation, so changes made by AddFunction, AddOperation and
+// AddConstant don't affect already compiled expressions.
+//
+// Expression is immutable and safe for concurrent use by multiple goroutines.
+type Expression struct {
+	source string
+	root   expression
+}
+
+// expression is the node of the Expression AST
+type expression interface {
+	evaluate(node *Node) (*Node, error)
+	write(sb *strings.Builder, parent uint8, right bool)
+}
+
+type (
+	exprLiteral struct {
+		source string
+		value  *Node
+	}
+
+	exprConstant struct {
+		name  string
+		value *Node
+	}
+
+	exprPath struct {
+		path *Path
+	}
+
+	exprFunction struct {
+		name     string
+		function Function
+		argument expression
+	}
+
+	exprOperation struct {
+		name        string
+		operation   Operation
+		priority    uint8
+		rightOp     bool
+		left, right expression
+	}
+)
+
+// errNothing stops the evaluation, if JSONPath in the expression found nothing
+var errNothing = errors.New("nothing found")
+
+// CompileExpression parses the script expression and prepares it to be evaluated.
+//
+// Example:
+//
+//	expr, err := CompileExpression("@.price * @.count > 100 && @.category == 'fiction'")
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, item := range items {
+//		result, _ := expr.Evaluate(item)
+//		fmt.Println(result.MustBool())
+//	}
+func CompileExpression(cmd string) (*Expression, error) {
+	calc, err := newBuffer([]byte(cmd)).rpn()
+	if err != nil {
+		return nil, err
+	}
+	root, err := compileRPN(calc, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: cmd, root: root}, nil
+}
+
+// MustCompileExpression is like CompileExpression but panics if the expression can't be parsed.
+func MustCompileExpression(cmd string) *Expression {
+	result, err := CompileExpression(cmd)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Evaluate calculates the expression for the node, same as Eval.
+func (e *Expression) Evaluate(node *Node) (result *Node, err error) {
+	result, err = e.root.evaluate(node)
+	if err == errNothing {
+		return NullNode(""), nil
+	}
+	return
+}
+
+// Source returns the source of the expression, which was compiled
+func (e *Expression) Source() string {
+	return e.source
+}
+
+// String returns the formatted expression, e.g.: `(@.price + 1) * 2 > $.expensive`
+func (e *Expression) String() string {
+	return exprString(e.root)
+}
+
+// compileRPN builds the AST from the reverse polish notation
+func compileRPN(calc rpn, cmd string) (result expression, err error) {
+	var (
+		stack []expression
+		size  int
+	)
+	for _, exp := range calc {
+		size = len(stack)
+		if fn, ok := functions[exp]; ok {
+			if size < 1 {
+				return nil, errorRequest("function '%s' has no argument in '%s'", exp, cmd)
+			}
+			stack[size-1] = &exprFunction{name: exp, function: fn, argument: stack[size-1]}
+		} else if op, ok := operations[exp]; ok {
+			if size < 2 {
+				return nil, errorRequest("operation '%s' expects 2 operands, got %d in '%s'", exp, size, cmd)
+			}
+			stack[size-2] = &exprOperation{
+				name:      exp,
+				operation: op,
+				priority:  priority[exp],
+				rightOp:   rightOp[exp],
+				left:      stack[size-2],
+				right:     stack[size-1],
+			}
+			stack = stack[:size-1]
+		} else {
+			if result, err = compileOperand(exp, cmd); err != nil {
+				return nil, err
+			}
+			stack = append(stack, result)
+		}
+	}
+	switch len(stack) {
+	case 0:
+		return nil, errorRequest("empty expression '%s'", cmd)
+	case 1:
+		return stack[0], nil
+	}
+	return nil, errorRequest("missing operation between '%s' and '%s' in '%s'", exprString(stack[0]), exprString(stack[1]), cmd)
+}
+
+// exprString returns the formatted node of the expression
+func exprString(exp expression) string {
+	var sb strings.Builder
+	exp.write(&sb, 0, false)
+	return sb.String()
+}
+
+// compileOperand compiles JSONPath, constant or literal value
+func compileOperand(exp string, cmd string) (expression, error) {
+	if len(exp) == 0 {
+		return &exprLiteral{source: "''", value: valueNode(nil, "", String, "")}, nil
+	}
+	if exp[0] == dollar || exp[0] == at {
+		path, err := CompileJSONPath(exp)
+		if err != nil {
+			return nil, err
+		}
+		return &exprPath{path: path}, nil
+	}
+	if constant, ok := constants[strings.ToLower(exp)]; ok {
+		return &exprConstant{name: strings.ToLower(exp), value: constant}, nil
+	}
+	var (
+		value *Node
+		err   error
+		bstr  = []byte(exp)
+		size  = len(bstr)
+	)
+	if size >= 2 && bstr[0] == quote && bstr[size-1] == quote {
+		if sstr, ok := unquote(bstr, quote); ok {
+			value = StringNode("", sstr)
+		} else {
+			return nil, errorRequest("wrong string %s in '%s'", exp, cmd)
+		}
+	} else if value, err = Unmarshal(bstr); err != nil {
+		return nil, errorRequest("wrong operand '%s' in '%s': %s", exp, cmd, err)
+	}
+	return &exprLiteral{source: exp, value: value}, nil
+}
+
+func (e *exprLiteral) evaluate(*Node) (*Node, error) {
+	return e.value, nil
+}
+
+func (e *exprLiteral) write(sb *strings.Builder, _ uint8, _ bool) {
+	sb.WriteString(e.source)
+}
+
+func (e *exprConstant) evaluate(*Node) (*Node, error) {
+	return e.value, nil
+}
+
+func (e *exprConstant) write(sb *strings.Builder, _ uint8, _ bool) {
+	sb.WriteString(e.name)
+}
+
+func (e *exprPath) evaluate(node *Node) (*Node, error) {
+	slice, err := e.path.Evaluate(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(slice) > 1 { // array given
+		for i, element := range slice {
+			slice[i] = detached(element)
+		}
+		return ArrayNode("", slice), nil
+	} else if len(slice) == 1 {
+		return slice[0], nil
+	}
+	return nil, errNothing
+}
+
+// detached returns the shallow copy of the node, which can be added to the new container without changes of the document
+func detached(node *Node) *Node {
+	result := &Node{
+		children: node.children,
+		keys:     node.keys,
+		_type:    node._type,
+		data:     node.data,
+		borders:  node.borders,
+		dirty:    node.dirty,
+	}
+	if value := node.value.Load(); value != nil {
+		result.value.Store(value)
+	}
+	return result
+}
+
+func (e *exprPath) write(sb *strings.Builder, _ uint8, _ bool) {
+	sb.WriteString(e.path.String())
+}
+
+func (e *exprFunction) evaluate(node *Node) (*Node, error) {
+	argument, err := e.argument.evaluate(node)
+	if err != nil {
+		return nil, err
+	}
+	return e.function(argument)
+}
+
+func (e *exprFunction) write(sb *strings.Builder, _ uint8, _ bool) {
+	sb.WriteString(e.name)
+	sb.WriteByte(parenthesesL)
+	e.argument.write(sb, 0, false)
+	sb.WriteByte(parenthesesR)
+}
+
+func (e *exprOperation) evaluate(node *Node) (*Node, error) {
+	left, err := e.left.evaluate(node)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.evaluate(node)
+	if err != nil {
+		return nil, err
+	}
+	return e.operation(left, right)
+}
+
+// write adds parentheses only if the operation has lower priority than the parent one,
+// or the same priority on the side, which is calculated later
+func (e *exprOperation) write(sb *strings.Builder, parent uint8, right bool) {
+	wrap := e.priority < parent || (e.priority == parent && right != e.rightOp)
+	if wrap {
+		sb.WriteByte(parenthesesL)
+	}
+	e.left.write(sb, e.priority, false)
+	sb.WriteByte(skipS)
+	sb.WriteString(e.name)
+	sb.WriteByte(skipS)
+	e.right.write(sb, e.priority, true)
+	if wrap {
+		sb.WriteByte(parenthesesR)
+	}
+}
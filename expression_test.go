@@ -0,0 +1,224 @@
+package ajson
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCompileExpression(t *testing.T) {
+	root := Must(Unmarshal(jsonExample))
+	tests := []struct {
+		name     string
+		cmd      string
+		expected *Node
+	}{
+		{name: "number", cmd: "2", expected: NumericNode("", 2)},
+		{name: "string", cmd: "'foo'", expected: StringNode("", "foo")},
+		{name: "double quoted string", cmd: `"foo"`, expected: StringNode("", "foo")},
+		{name: "constant", cmd: "PI", expected: NumericNode("", 3.141592653589793)},
+		{name: "math", cmd: "2 + 2 * 2", expected: NumericNode("", 6)},
+		{name: "parentheses", cmd: "(2 + 2) * 2", expected: NumericNode("", 8)},
+		{name: "power", cmd: "2 ** 3 ** 2", expected: NumericNode("", 512)},
+		{name: "function", cmd: "round(avg($..price))", expected: NumericNode("", 15)},
+		{name: "path", cmd: "$.store.bicycle.color", expected: StringNode("", "red")},
+		{name: "path array", cmd: "length($..book[?(@.isbn)])", expected: NumericNode("", 2)},
+		{name: "path not found", cmd: "$.unknown + 1", expected: NullNode("")},
+		{name: "logical", cmd: "$.store.bicycle.price > 5 && $.store.bicycle.color == 'red'", expected: BoolNode("", true)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := CompileExpression(test.cmd)
+			if err != nil {
+				t.Fatalf("CompileExpression() error = %v", err)
+			}
+			if expr.Source() != test.cmd {
+				t.Errorf("Source() = %s, expected %s", expr.Source(), test.cmd)
+			}
+			expected, err := Eval(root, test.cmd)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				result, err := expr.Evaluate(root)
+				if err != nil {
+					t.Fatalf("Evaluate() error = %v", err)
+				}
+				if ok, err := result.Eq(test.expected); err != nil || !ok {
+					t.Errorf("Evaluate() = %v, expected %v", result, test.expected)
+				}
+				if ok, err := result.Eq(expected); err != nil || !ok {
+					t.Errorf("Evaluate() = %v, Eval() = %v", result, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileExpression_error(t *testing.T) {
+	tests := []string{
+		"",
+		"2 +",
+		"1 2",
+		"(2 + 3",
+		"2 + 3)",
+		"foo(1)",
+		"foo",
+		"'foo",
+		"$[1",
+	}
+	for _, cmd := range tests {
+		t.Run(cmd, func(t *testing.T) {
+			if _, err := CompileExpression(cmd); err == nil {
+				t.Errorf("CompileExpression() expected error")
+			}
+		})
+	}
+}
+
+func TestCompileExpression_errorMessage(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected string
+	}{
+		{cmd: "2 +", expected: "wrong request: operation '+' expects 2 operands, got 1 in '2 +'"},
+		{cmd: "1 2", expected: "wrong request: missing operation between '1' and '2' in '1 2'"},
+		{cmd: "@.a 2 + 3", expected: "wrong request: missing operation between '@.a' and '2 + 3' in '@.a 2 + 3'"},
+		{cmd: "sin()", expected: "wrong request: function 'sin' has no argument in 'sin()'"},
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			_, err := CompileExpression(test.cmd)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("CompileExpression() error = %v, expected %s", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestExpression_Evaluate_error(t *testing.T) {
+	expr := MustCompileExpression("@.value / 0")
+	if _, err := expr.Evaluate(Must(Unmarshal([]byte(`{"value": 1}`)))); err == nil {
+		t.Errorf("Evaluate() expected error")
+	}
+}
+
+func TestExpression_Evaluate_document(t *testing.T) {
+	root := Must(Unmarshal(jsonExample))
+	if _, err := MustCompileExpression("avg($..book[*].price)").Evaluate(root); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	nodes, err := root.JSONPath("$..book[*].price")
+	if err != nil {
+		t.Fatalf("JSONPath() error = %v", err)
+	}
+	if len(nodes) != 4 || nodes[3].Path() != "$['store']['book'][3]['price']" {
+		t.Errorf("Evaluate() changed the document: %v", Paths(nodes))
+	}
+}
+
+func TestExpression_String(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected string
+	}{
+		{cmd: "1", expected: "1"},
+		{cmd: "1+2", expected: "1 + 2"},
+		{cmd: "1 + 2 * 3", expected: "1 + 2 * 3"},
+		{cmd: "(1 + 2) * 3", expected: "(1 + 2) * 3"},
+		{cmd: "((1 * 2)) + 3", expected: "1 * 2 + 3"},
+		{cmd: "1 - (2 - 3)", expected: "1 - (2 - 3)"},
+		{cmd: "(1 - 2) - 3", expected: "1 - 2 - 3"},
+		{cmd: "2 ** 3 ** 2", expected: "2 ** 3 ** 2"},
+		{cmd: "(2 ** 3) ** 2", expected: "(2 ** 3) ** 2"},
+		{cmd: "SQRT(@.a * @.a+@.b * @.b)", expected: "sqrt(@.a * @.a + @.b * @.b)"},
+		{cmd: "@.price<10&&@.category=='fiction'||$.flag", expected: "@.price < 10 && @.category == 'fiction' || $.flag"},
+		{cmd: "@.price < (10 || $.flag)", expected: "@.price < (10 || $.flag)"},
+		{cmd: "E * -1.5e3", expected: "e * -1.5e3"},
+		{cmd: `length($..book[?(@.price < 10)]) == "two"`, expected: `length($..book[?(@.price < 10)]) == "two"`},
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			expr, err := CompileExpression(test.cmd)
+			if err != nil {
+				t.Fatalf("CompileExpression() error = %v", err)
+			}
+			if result := expr.String(); result != test.expected {
+				t.Errorf("String() = %s, expected %s", result, test.expected)
+			}
+			if _, err = CompileExpression(expr.String()); err != nil {
+				t.Errorf("CompileExpression(String()) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestExpression_Evaluate_concurrent(t *testing.T) {
+	expr := MustCompileExpression("@.price * @.count > 100")
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			node := Must(Unmarshal([]byte(fmt.Sprintf(`{"price": 10, "count": %d}`, i))))
+			for j := 0; j < 10; j++ {
+				result, err := expr.Evaluate(node)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if result.MustBool() != (i > 10) {
+					errs <- fmt.Errorf("wrong result for %d: %v", i, result)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Evaluate() error = %v", err)
+	}
+}
+
+func TestMustCompileExpression(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompileExpression() expected panic")
+		}
+	}()
+	MustCompileExpression("2 +")
+}
+
+func BenchmarkExpression_Evaluate(b *testing.B) {
+	root := Must(Unmarshal(jsonPathTestData))
+	expr := MustCompileExpression("avg($..price) > $.expensive && $.store.bicycle.color == 'red'")
+	for i := 0; i < b.N; i++ {
+		if _, err := expr.Evaluate(root); err != nil {
+			b.Error()
+		}
+	}
+}
+
+func ExampleCompileExpression() {
+	expr := MustCompileExpression("@.price * @.count>=100 && @.category=='fiction'")
+	orders := []string{
+		`{"price": 8.95, "count": 10, "category": "reference"}`,
+		`{"price": 12.99, "count": 10, "category": "fiction"}`,
+		`{"price": 22.99, "count": 1, "category": "fiction"}`,
+	}
+	fmt.Println(expr)
+	for _, order := range orders {
+		result, err := expr.Evaluate(Must(Unmarshal([]byte(order))))
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(result.MustBool())
+	}
+	// Output:
+	// @.price * @.count >= 100 && @.category == 'fiction'
+	// false
+	// true
+	// false
+}
@@ -0,0 +1,420 @@
+package ajson
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	. "github.com/spyzhov/ajson/internal"
+)
+
+// ParseOptions are the options of the UnmarshalWithOptions, which relax the JSON syntax.
+type ParseOptions struct {
+	// JSON5 enables all the options below and the JSON5 whitespaces, e.g.: U+2028, U+2029 and BOM, see https://json5.org
+	JSON5 bool
+	// AllowComments allows `// line` and `/* block */` comments between the tokens.
+	AllowComments bool
+	// AllowTrailingCommas allows the comma after the last element of objects and arrays.
+	AllowTrailingCommas bool
+	// AllowRelaxedStrings allows single-quoted strings and JSON5 escapes: `\'`, `\v`, `\0`, `\xFF`,
+	// escaped line breaks and escaped characters without special meaning.
+	AllowRelaxedStrings bool
+	// AllowUnquotedKeys allows identifiers as object keys, e.g.: `{key: 1}`.
+	AllowUnquotedKeys bool
+	// AllowRelaxedNumbers allows hexadecimal numbers, leading and trailing decimal points, the plus sign,
+	// `Infinity` and `NaN`.
+	AllowRelaxedNumbers bool
+	// KeepComments allows comments and attaches them to the nodes, see Node.Comments. Parsed nodes are not changed by
+	// the relaxed syntax, so Marshal writes untouched parts of the data as is, with comments and the original
+	// formatting, and splices only changed nodes into them.
+	KeepComments bool
+}
+
+// UnmarshalWithOptions parses the data same as Unmarshal, but accepts the relaxed syntax, enabled by the options.
+//
+// Relaxed values are stored as the strict JSON, so Marshal always returns the strict JSON: comments and trailing
+// commas are dropped, strings are double-quoted, numbers are decimal, and `Infinity` and `NaN` are marshaled as
+// `null`, same as `JSON.stringify` does. Containers with the relaxed syntax are marked as changed.
+// With the KeepComments option, the relaxed syntax is kept in the source of untouched nodes instead.
+//
+// Example:
+//
+//	root, err := UnmarshalWithOptions([]byte(`{name: 'ajson', /* hex */ mask: 0xFF, tags: ['a', 'b',],}`), ParseOptions{JSON5: true})
+//	result, _ := Marshal(root)
+//	// {"name":"ajson","mask":255,"tags":["a","b"]}
+func UnmarshalWithOptions(data []byte, options ParseOptions) (root *Node, err error) {
+	if options.JSON5 {
+		options = ParseOptions{
+			JSON5:               true,
+			AllowComments:       true,
+			AllowTrailingCommas: true,
+			AllowRelaxedStrings: true,
+			AllowUnquotedKeys:   true,
+			AllowRelaxedNumbers: true,
+			KeepComments:        options.KeepComments,
+		}
+	}
+	if options.KeepComments {
+		options.AllowComments = true
+	}
+	root, err = unmarshal(data, nil, &options)
+	if err == nil && options.KeepComments {
+		attach(root, &trivia{data: root.data, key: root.borders[0], colon: root.borders[0], value: root.borders, comma: -1, end: len(data)})
+	}
+	return root, err
+}
+
+// relax parses the relaxed syntax at the current symbol, enabled by the options. Returns GO, if the symbol was
+// parsed, or the next state of the strict syntax otherwise.
+func relax(buf *buffer, options *ParseOptions, current **Node, key **string) (States, error) {
+	c := buf.data[buf.index]
+	node := *current
+	value := (buf.state == GO && node == nil) || buf.state == VA || buf.state == AR
+	object := (buf.state == OB || buf.state == KE) && node != nil && node.IsObject()
+	switch {
+	case options.AllowComments && c == division:
+		end, err := skipComment(buf.data, buf.index)
+		if err != nil {
+			return GO, err
+		}
+		buf.index = end - 1
+		if node != nil && !node.ready() {
+			options.mark(node)
+		}
+		return GO, nil
+	case options.JSON5 && json5Space(buf.data, buf.index) > 0:
+		buf.index += json5Space(buf.data, buf.index) - 1
+		if node != nil && !node.ready() {
+			options.mark(node)
+		}
+		return GO, nil
+	case options.AllowTrailingCommas && node != nil && !node.ready() &&
+		(c == bracesR && buf.state == KE && node.IsObject() || c == bracketR && buf.state == VA && node.IsArray()):
+		options.mark(node)
+		buf.state = OK
+	case options.AllowRelaxedStrings && (value || object) && (c == quote || c == quotes):
+		text, end, strict, err := json5String(buf.data, buf.index)
+		if err != nil {
+			if value {
+				*current, _ = newNode(node, buf, String, key)
+			}
+			buf.state = ST
+			return GO, err
+		}
+		if object {
+			if !strict {
+				options.mark(node)
+			}
+			*key = &text
+			buf.index = end - 1
+			buf.state = CO
+			return GO, nil
+		}
+		if strict {
+			break
+		}
+		source := append(append([]byte{quotes}, quoteString(text, false, false)...), quotes)
+		_, err = relaxed(buf, options, current, key, String, end, source, text)
+		return GO, err
+	case options.AllowUnquotedKeys && object && identifierEnd(buf.data, buf.index) > buf.index:
+		end := identifierEnd(buf.data, buf.index)
+		text := string(buf.data[buf.index:end])
+		options.mark(node)
+		*key = &text
+		buf.index = end - 1
+		buf.state = CO
+		return GO, nil
+	case (options.AllowRelaxedNumbers || options.AllowComments) && value &&
+		(c == minus || c >= '0' && c <= '9' || options.AllowRelaxedNumbers && (c == plus || c == dot || c == 'I' || c == 'N')):
+		// numbers are parsed here, to be stopped by the comment without spaces: `1/* one */`
+		end := buf.index
+		for end < buf.length && numberSymbol(buf.data[end]) {
+			end++
+		}
+		token := string(buf.data[buf.index:end])
+		number, ok := token, strictNumber(token)
+		if !ok && options.AllowRelaxedNumbers {
+			number, ok = json5Number(token)
+		}
+		if !ok {
+			*current, _ = newNode(node, buf, Numeric, key)
+			buf.state = MI
+			return GO, errorSymbol(buf)
+		}
+		if number == token {
+			_, err := relaxed(buf, options, current, key, Numeric, end, nil, nil)
+			return GO, err
+		}
+		if number != "" {
+			value, _ := strconv.ParseFloat(number, 64)
+			_, err := relaxed(buf, options, current, key, Numeric, end, []byte(number), value)
+			return GO, err
+		}
+		// Infinity or NaN
+		value := math.NaN()
+		if strings.HasSuffix(token, "Infinity") {
+			value = math.Inf(1)
+			if token[0] == minus {
+				value = math.Inf(-1)
+			}
+		}
+		child, err := relaxed(buf, options, current, key, Numeric, end, nil, value)
+		if err == nil && !options.KeepComments {
+			// there is no such number in JSON, so it is bound to the token and always encoded from the value
+			source := []byte(token)
+			child.data = &source
+			child.borders = [2]int{0, len(source)}
+			child.mark()
+		}
+		return GO, err
+	}
+	return buf.getState(), nil
+}
+
+// relaxed adds the scalar node, which ends before the end. If the source is set, the node is bound to it instead
+// of the data, and the parent is marked as changed. With the KeepComments option, the node keeps the data as the
+// source, and the value is stored instead.
+func relaxed(buf *buffer, options *ParseOptions, current **Node, key **string, _type NodeType, end int, source []byte, value interface{}) (node *Node, err error) {
+	node, err = newNode(*current, buf, _type, key)
+	*current = node
+	if err != nil {
+		return nil, err
+	}
+	node.borders[1] = end
+	if value != nil {
+		node.value.Store(value)
+	}
+	if source != nil && !options.KeepComments {
+		node.data = &source
+		node.borders = [2]int{0, len(source)}
+		if node.parent != nil {
+			node.parent.mark()
+		}
+	}
+	buf.index = end - 1
+	buf.state = OK
+	if node.parent != nil {
+		*current = node.parent
+	}
+	return node, nil
+}
+
+// mark marks the node as changed by the relaxed syntax, unless the source is kept.
+func (o *ParseOptions) mark(node *Node) {
+	if !o.KeepComments {
+		node.mark()
+	}
+}
+
+// skipComment returns the index after the end of the comment, which starts at the index.
+func skipComment(data []byte, index int) (int, error) {
+	if index+1 >= len(data) {
+		return index, errorEOFAt(len(data))
+	}
+	switch data[index+1] {
+	case division:
+		for index < len(data) && data[index] != skipN {
+			index++
+		}
+		return index, nil
+	case asterisk:
+		for i := index + 2; i+1 < len(data); i++ {
+			if data[i] == asterisk && data[i+1] == division {
+				return i + 2, nil
+			}
+		}
+		return index, errorEOFAt(len(data))
+	}
+	return index, errorAt(index+1, data[index+1])
+}
+
+// json5Space returns the size of the JSON5 whitespace at the index, which is not the JSON whitespace, or 0.
+func json5Space(data []byte, index int) int {
+	if data[index] < utf8.RuneSelf {
+		if data[index] == '\v' || data[index] == '\f' {
+			return 1
+		}
+		return 0
+	}
+	r, size := utf8.DecodeRune(data[index:])
+	if r == '\u2028' || r == '\u2029' || r == '\uFEFF' || unicode.Is(unicode.Zs, r) {
+		return size
+	}
+	return 0
+}
+
+// identifierEnd returns the index after the end of the ECMAScript identifier, which starts at the index.
+func identifierEnd(data []byte, index int) int {
+	start := index
+	for index < len(data) {
+		r, size := utf8.DecodeRune(data[index:])
+		if !(r == '$' || r == '_' || unicode.IsLetter(r) ||
+			index != start && (unicode.In(r, unicode.Nd, unicode.Mn, unicode.Mc, unicode.Pc) || r == '\u200C' || r == '\u200D')) {
+			break
+		}
+		index += size
+	}
+	return index
+}
+
+// numberSymbol checks if the symbol can be a part of the JSON5 number
+func numberSymbol(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == dot || c == plus || c == minus
+}
+
+// strictNumber checks if the token is the JSON number
+func strictNumber(token string) bool {
+	buf := newBuffer([]byte(token))
+	return buf.numeric(false) == nil && buf.index == buf.length
+}
+
+// json5Number returns the JSON number, equal to the JSON5 number token, or the empty string for `Infinity` and `NaN`.
+func json5Number(token string) (string, bool) {
+	sign, body := "", token
+	if body[0] == plus || body[0] == minus {
+		if body[0] == minus {
+			sign = "-"
+		}
+		body = body[1:]
+	}
+	switch {
+	case body == "Infinity" || body == "NaN":
+		return "", true
+	case strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X"):
+		value, ok := new(big.Int).SetString(body[2:], 16)
+		if !ok || body[2] == plus || body[2] == minus {
+			return "", false
+		}
+		return sign + value.String(), true
+	}
+	if strings.HasPrefix(body, ".") {
+		if len(body) == 1 || body[1] < '0' || body[1] > '9' {
+			return "", false
+		}
+		body = "0" + body
+	}
+	if i := strings.IndexAny(body, "eE"); i > 0 && body[i-1] == dot {
+		body = body[:i-1] + body[i:]
+	} else if strings.HasSuffix(body, ".") {
+		body = body[:len(body)-1]
+	}
+	body = sign + body
+	return body, strictNumber(body)
+}
+
+// json5String reads the single- or double-quoted JSON5 string, which starts at the index. Returns the value,
+// the index after the closing quote, and false as strict, if the string is not the valid JSON string.
+func json5String(data []byte, index int) (value string, end int, strict bool, err error) {
+	border := data[index]
+	strict = border == quotes
+	result := make([]byte, 0)
+	for i := index + 1; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == border:
+			return string(result), i + 1, strict, nil
+		case c == skipN || c == '\r':
+			return "", i, false, errorAt(i, c)
+		case c < ' ':
+			strict = false
+			result = append(result, c)
+		case c == backslash:
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch c = data[i]; c {
+			case quotes, backslash, division:
+				result = append(result, c)
+			case 'b':
+				result = append(result, '\b')
+			case 'f':
+				result = append(result, '\f')
+			case 'n':
+				result = append(result, '\n')
+			case 'r':
+				result = append(result, '\r')
+			case 't':
+				result = append(result, '\t')
+			case 'u':
+				r, ok := hexRune(data, i+1, 4)
+				if !ok {
+					return "", i, false, errorAt(i, c)
+				}
+				i += 4
+				if utf16.IsSurrogate(r) {
+					if low, ok := hexRune(data, i+3, 4); ok && i+2 < len(data) && data[i+1] == backslash && data[i+2] == 'u' {
+						if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+							r = pair
+							i += 6
+						}
+					}
+				}
+				result = append(result, string(r)...)
+			case 'x':
+				r, ok := hexRune(data, i+1, 2)
+				if !ok {
+					return "", i, false, errorAt(i, c)
+				}
+				i += 2
+				strict = false
+				result = append(result, string(r)...)
+			case 'v':
+				strict = false
+				result = append(result, '\v')
+			case '0':
+				if i+1 < len(data) && data[i+1] >= '0' && data[i+1] <= '9' {
+					return "", i, false, errorAt(i, c)
+				}
+				strict = false
+				result = append(result, 0)
+			case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				return "", i, false, errorAt(i, c)
+			case '\r':
+				// escaped line break is skipped
+				strict = false
+				if i+1 < len(data) && data[i+1] == skipN {
+					i++
+				}
+			case skipN:
+				strict = false
+			default:
+				// any other escaped symbol is the symbol itself, including U+2028 and U+2029, which are line breaks
+				strict = false
+				r, size := utf8.DecodeRune(data[i:])
+				if r != '\u2028' && r != '\u2029' {
+					result = append(result, data[i:i+size]...)
+				}
+				i += size - 1
+			}
+		default:
+			result = append(result, c)
+		}
+	}
+	return "", len(data), false, errorEOFAt(len(data))
+}
+
+// hexRune returns the rune of the size hexadecimal digits, which start at the index.
+func hexRune(data []byte, index, size int) (r rune, ok bool) {
+	if index+size > len(data) {
+		return 0, false
+	}
+	for _, c := range data[index : index+size] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
@@ -0,0 +1,171 @@
+package ajson
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	json5 := ParseOptions{JSON5: true}
+	tests := []struct {
+		name     string
+		data     string
+		options  ParseOptions
+		expected string
+		err      bool
+	}{
+		{name: "strict", data: `{"a": [1, "b"]}`, expected: `{"a": [1, "b"]}`},
+		{name: "no options", data: `[1,]`, err: true},
+		{name: "line comment", data: "{\n// comment\n\"a\": 1 // one\n}", options: ParseOptions{AllowComments: true}, expected: `{"a":1}`},
+		{name: "block comment", data: `[1/* one */, /* two */2]`, options: ParseOptions{AllowComments: true}, expected: `[1,2]`},
+		{name: "root comments", data: "/* a */ 1 // b", options: ParseOptions{AllowComments: true}, expected: `1`},
+		{name: "comment after key", data: `{"a" /* a */ : true}`, options: ParseOptions{AllowComments: true}, expected: `{"a":true}`},
+		{name: "unclosed comment", data: `[1 /* one ]`, options: ParseOptions{AllowComments: true}, err: true},
+		{name: "wrong comment", data: `[1 / 2]`, options: ParseOptions{AllowComments: true}, err: true},
+		{name: "comments disabled", data: `[1 /* one */]`, options: ParseOptions{AllowTrailingCommas: true}, err: true},
+		{name: "trailing commas", data: `{"a": [1, 2,], "b": {},}`, options: ParseOptions{AllowTrailingCommas: true}, expected: `{"a":[1,2],"b":{}}`},
+		{name: "only comma", data: `[,]`, options: ParseOptions{AllowTrailingCommas: true}, err: true},
+		{name: "double comma", data: `[1,,]`, options: ParseOptions{AllowTrailingCommas: true}, err: true},
+		{name: "missing value", data: `{"a":}`, options: ParseOptions{AllowTrailingCommas: true}, err: true},
+		{name: "single quotes", data: `{'a': 'it\'s "b"'}`, options: ParseOptions{AllowRelaxedStrings: true}, expected: `{"a":"it's \"b\""}`},
+		{name: "escapes", data: `["\x41\v\0\qB😀", "a\` + "\n" + `b"]`, options: ParseOptions{AllowRelaxedStrings: true}, expected: `["A\u000b\u0000qB😀","ab"]`},
+		{name: "strict string", data: `["aA"]`, options: ParseOptions{AllowRelaxedStrings: true}, expected: `["aA"]`},
+		{name: "string line break", data: "['a\nb']", options: ParseOptions{AllowRelaxedStrings: true}, err: true},
+		{name: "octal escape", data: `['\01']`, options: ParseOptions{AllowRelaxedStrings: true}, err: true},
+		{name: "wrong hex escape", data: `['\xZ1']`, options: ParseOptions{AllowRelaxedStrings: true}, err: true},
+		{name: "unclosed string", data: `['a]`, options: ParseOptions{AllowRelaxedStrings: true}, err: true},
+		{name: "unquoted keys", data: `{a: 1, $b_2: {ключ: 3}}`, options: ParseOptions{AllowUnquotedKeys: true}, expected: `{"a":1,"$b_2":{"ключ":3}}`},
+		{name: "unquoted key digit", data: `{1a: 1}`, options: ParseOptions{AllowUnquotedKeys: true}, err: true},
+		{name: "unquoted value", data: `{"a": b}`, options: ParseOptions{AllowUnquotedKeys: true}, err: true},
+		{name: "numbers", data: `[0xFF, -0x10, +1, .5, 5., 1.e2, -.5e-1, 1e+2]`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `[255,-16,1,0.5,5,1e2,-0.5e-1,1e+2]`},
+		{name: "big hex", data: `0xFFFFFFFFFFFFFFFFFFFF`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `1208925819614629174706175`},
+		{name: "not finite", data: `[Infinity, -Infinity, +Infinity, NaN]`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `[null,null,null,null]`},
+		{name: "root Infinity", data: `Infinity`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `null`},
+		{name: "root -Infinity", data: `-Infinity`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `null`},
+		{name: "root +Infinity", data: `+Infinity`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `null`},
+		{name: "root NaN", data: `NaN`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `null`},
+		{name: "root +NaN", data: `+NaN`, options: ParseOptions{AllowRelaxedNumbers: true}, expected: `null`},
+		{name: "root NaN comment", data: `NaN // not a number`, options: ParseOptions{AllowRelaxedNumbers: true, AllowComments: true}, expected: `null`},
+		{name: "wrong hex", data: `[0x]`, options: ParseOptions{AllowRelaxedNumbers: true}, err: true},
+		{name: "leading zero", data: `[01]`, options: ParseOptions{AllowRelaxedNumbers: true}, err: true},
+		{name: "wrong number", data: `[1a]`, options: ParseOptions{AllowRelaxedNumbers: true}, err: true},
+		{name: "only dot", data: `[.]`, options: ParseOptions{AllowRelaxedNumbers: true}, err: true},
+		{name: "numbers disabled", data: `[0x1]`, options: ParseOptions{AllowComments: true}, err: true},
+		{
+			name: "json5",
+			data: `// config
+{
+	name: 'ajson', /* the name */
+	"version": +1.0,
+	mask: 0xFF,
+	limit: Infinity,
+	tags: ['a', "b",],
+}`,
+			options:  json5,
+			expected: `{"name":"ajson","version":1.0,"mask":255,"limit":null,"tags":["a","b"]}`,
+		},
+		{name: "json5 error", data: `{a: 1,, }`, options: json5, err: true},
+		{name: "json5 whitespaces", data: "\uFEFF{a:\u2028 1,\u2029b: [\u00A01\v,\f2]}\uFEFF", options: json5, expected: `{"a":1,"b":[1,2]}`},
+		{name: "json5 root whitespaces", data: "\uFEFF\u2028Infinity\u2029", options: json5, expected: `null`},
+		{name: "whitespaces disabled", data: "[\u2028 1]", options: ParseOptions{AllowComments: true}, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.data), test.options)
+			if test.err {
+				if err == nil {
+					t.Errorf("UnmarshalWithOptions() expected error, got %s", root)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalWithOptions() error = %v", err)
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("Marshal() = %s, expected %s", result, test.expected)
+			}
+			if _, err = Unmarshal(result); err != nil {
+				t.Errorf("Marshal() returns wrong JSON: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_values(t *testing.T) {
+	root, err := UnmarshalWithOptions([]byte(`{a: 'b\'c', b: 0x10, c: -Infinity, d: NaN, e: .5}`), ParseOptions{JSON5: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	if value := root.MustKey("a").MustString(); value != "b'c" {
+		t.Errorf("a = %q, expected %q", value, "b'c")
+	}
+	if value := root.MustKey("b").MustNumeric(); value != 16 {
+		t.Errorf("b = %v, expected 16", value)
+	}
+	if value := root.MustKey("c").MustNumeric(); !math.IsInf(value, -1) {
+		t.Errorf("c = %v, expected -Inf", value)
+	}
+	if value := root.MustKey("d").MustNumeric(); !math.IsNaN(value) {
+		t.Errorf("d = %v, expected NaN", value)
+	}
+	if value := root.MustKey("e").MustNumeric(); value != 0.5 {
+		t.Errorf("e = %v, expected 0.5", value)
+	}
+}
+
+func TestUnmarshalWithOptions_root(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected float64
+	}{
+		{data: `Infinity`, expected: math.Inf(1)},
+		{data: `-Infinity`, expected: math.Inf(-1)},
+		{data: `+Infinity`, expected: math.Inf(1)},
+		{data: `NaN`, expected: math.NaN()},
+		{data: `+NaN`, expected: math.NaN()},
+	}
+	for _, test := range tests {
+		for _, keep := range []bool{false, true} {
+			root, err := UnmarshalWithOptions([]byte(test.data), ParseOptions{JSON5: true, KeepComments: keep})
+			if err != nil {
+				t.Errorf("UnmarshalWithOptions(%s, %v) error = %v", test.data, keep, err)
+				continue
+			}
+			value, err := root.GetNumeric()
+			if err != nil {
+				t.Errorf("GetNumeric(%s, %v) error = %v", test.data, keep, err)
+			} else if value != test.expected && !(math.IsNaN(value) && math.IsNaN(test.expected)) {
+				t.Errorf("GetNumeric(%s, %v) = %v, expected %v", test.data, keep, value, test.expected)
+			}
+		}
+	}
+}
+
+func TestUnmarshalWithOptions_error(t *testing.T) {
+	_, err := UnmarshalWithOptions([]byte("{\n\ta: 'b',\n\tc: 0xZ,\n}"), ParseOptions{JSON5: true})
+	expected := "wrong symbol '0' at 15 on line 3, column 5 in $['c']: expected valid number"
+	if err == nil || err.Error() != expected {
+		t.Errorf("UnmarshalWithOptions() error = %v, expected %s", err, expected)
+	}
+}
+
+func ExampleUnmarshalWithOptions() {
+	root, err := UnmarshalWithOptions([]byte(`{
+	// the name of the package
+	name: 'ajson',
+	mask: 0xFF, /* hexadecimal */
+	tags: ['a', 'b',],
+}`), ParseOptions{JSON5: true})
+	if err != nil {
+		panic(err)
+	}
+	result, _ := Marshal(root)
+	fmt.Printf("%s", result)
+	// Output:
+	// {"name":"ajson","mask":255,"tags":["a","b"]}
+}
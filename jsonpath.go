@@ -2,9 +2,6 @@ package ajson
 
 import (
 	"io"
-	"math"
-	"strconv"
-	"strings"
 )
 
 // JSONPath returns slice of founded elements in current JSON data, by it's JSONPath.
@@ -158,7 +155,7 @@ import (
 //     y1           math.Y1           integers, floats
 //
 func JSONPath(data []byte, path string) (result []*Node, err error) {
-	commands, err := ParseJSONPath(path)
+	compiled, err := CompileJSONPath(path)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +163,7 @@ func JSONPath(data []byte, path string) (result []*Node, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return deReference(node, commands)
+	return compiled.Evaluate(node)
 }
 
 // Paths returns calculated paths of underlying nodes
@@ -201,6 +198,12 @@ func recursiveChildren(node *Node) (result []*Node) {
 // 	result == []string{"$", "store", "book", "?(@.price < 10)", "title"}
 //
 func ParseJSONPath(path string) (result []string, err error) {
+	result, _, err = parseJSONPath(path)
+	return
+}
+
+// parseJSONPath returns commands of the path and offsets of their texts in the path
+func parseJSONPath(path string) (result []string, offsets []int, err error) {
 	buf := newBuffer([]byte(path))
 	result = make([]string, 0)
 	const (
@@ -223,6 +226,7 @@ func ParseJSONPath(path string) (result []string, err error) {
 		switch true {
 		case c == dollar || c == at:
 			result = append(result, string(c))
+			offsets = append(offsets, buf.index)
 		case c == dot:
 			start = buf.index
 			c, err = buf.next()
@@ -235,6 +239,7 @@ func ParseJSONPath(path string) (result []string, err error) {
 			}
 			if c == dot {
 				result = append(result, "..")
+				offsets = append(offsets, start)
 				buf.index--
 				break
 			}
@@ -251,11 +256,12 @@ func ParseJSONPath(path string) (result []string, err error) {
 			}
 			if start+1 < stop {
 				result = append(result, string(buf.data[start+1:stop]))
+				offsets = append(offsets, start+1)
 			}
 		case c == bracketL:
 			_, err = buf.next()
 			if err != nil {
-				return nil, buf.errorEOF()
+				return nil, nil, buf.errorEOF()
 			}
 			brackets = 1
 			start = buf.index
@@ -288,13 +294,14 @@ func ParseJSONPath(path string) (result []string, err error) {
 					}
 					if brackets == 0 {
 						result = append(result, string(buf.data[start:buf.index]))
+						offsets = append(offsets, start)
 						break parseSwitch
 					}
 				}
 			}
-			return nil, buf.errorEOF()
+			return nil, nil, buf.errorEOF()
 		default:
-			return nil, buf.errorSymbol()
+			return nil, nil, buf.errorSymbol()
 		}
 		err = buf.step()
 		if err != nil {
@@ -308,387 +315,22 @@ func ParseJSONPath(path string) (result []string, err error) {
 }
 
 func deReference(node *Node, commands []string) (result []*Node, err error) {
-	result = make([]*Node, 0)
-	var (
-		temporary   []*Node
-		keys        []string
-		ikeys       [3]int
-		fkeys       [3]float64
-		num         int
-		key         string
-		ok          bool
-		value, temp *Node
-		float       float64
-		tokens      tokens
-		expr        rpn
-	)
-	for i, cmd := range commands {
-		tokens, err = newBuffer([]byte(cmd)).tokenize()
-		if err != nil {
-			return
-		}
-		switch {
-		case cmd == "$": // root element
-			if i == 0 {
-				result = append(result, node.root())
-			}
-		case cmd == "@": // current element
-			if i == 0 {
-				result = append(result, node)
-			}
-		case cmd == "..": // recursive descent
-			temporary = make([]*Node, 0)
-			for _, element := range result {
-				temporary = append(temporary, recursiveChildren(element)...)
-			}
-			result = append(result, temporary...)
-		case cmd == "*": // wildcard
-			temporary = make([]*Node, 0)
-			for _, element := range result {
-				temporary = append(temporary, element.Inheritors()...)
-			}
-			result = temporary
-		case tokens.exists(":"): // array slice operator
-			if tokens.count(":") > 3 {
-				return nil, errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
-			}
-			keys = tokens.slice(":")
-
-			temporary = make([]*Node, 0)
-			for _, element := range result {
-				if element.IsArray() && element.Size() > 0 {
-					if fkeys[0], err = getNumberIndex(element, keys[0], math.NaN()); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-					if fkeys[1], err = getNumberIndex(element, keys[1], math.NaN()); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-					if len(keys) < 3 {
-						fkeys[2] = 1
-					} else if fkeys[2], err = getNumberIndex(element, keys[2], 1); err != nil {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-
-					ikeys[2] = int(fkeys[2])
-					if ikeys[2] == 0 {
-						return nil, errorRequest("wrong request: %s", cmd)
-					}
-
-					if math.IsNaN(fkeys[0]) {
-						if ikeys[2] > 0 {
-							ikeys[0] = 0
-						} else {
-							ikeys[0] = element.Size() - 1
-						}
-					} else {
-						ikeys[0] = getPositiveIndex(int(fkeys[0]), element.Size())
-					}
-					if math.IsNaN(fkeys[1]) {
-						if ikeys[2] > 0 {
-							ikeys[1] = element.Size()
-						} else {
-							ikeys[1] = -1
-						}
-					} else {
-						ikeys[1] = getPositiveIndex(int(fkeys[1]), element.Size())
-					}
-
-					if ikeys[2] > 0 {
-						if ikeys[0] < 0 {
-							ikeys[0] = 0
-						}
-						if ikeys[1] > element.Size() {
-							ikeys[1] = element.Size()
-						}
-
-						for i := ikeys[0]; i < ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
-							}
-						}
-					} else if ikeys[2] < 0 {
-						if ikeys[0] > element.Size() {
-							ikeys[0] = element.Size()
-						}
-						if ikeys[1] < -1 {
-							ikeys[1] = -1
-						}
-
-						for i := ikeys[0]; i > ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
-							}
-						}
-					}
-				}
-			}
-			result = temporary
-		case strings.HasPrefix(cmd, "?(") && strings.HasSuffix(cmd, ")"): // applies a filter (script) expression
-			expr, err = newBuffer([]byte(cmd[2 : len(cmd)-1])).rpn()
-			if err != nil {
-				return nil, errorRequest("wrong request: %s", cmd)
-			}
-			temporary = make([]*Node, 0)
-			for _, element := range result {
-				if element.isContainer() {
-					for _, temp = range element.Inheritors() {
-						value, err = eval(temp, expr, cmd)
-						if err != nil {
-							return nil, errorRequest("wrong request: %s", cmd)
-						}
-						if value != nil {
-							ok, err = boolean(value)
-							if err != nil || !ok {
-								continue
-							}
-							temporary = append(temporary, temp)
-						}
-					}
-				}
-			}
-			result = temporary
-		case strings.HasPrefix(cmd, "(") && strings.HasSuffix(cmd, ")"): // script expression, using the underlying script engine
-			expr, err = newBuffer([]byte(cmd[1 : len(cmd)-1])).rpn()
-			if err != nil {
-				return nil, errorRequest("wrong request: %s", cmd)
-			}
-			temporary = make([]*Node, 0)
-			for _, element := range result {
-				if !element.isContainer() {
-					continue
-				}
-				temp, err = eval(element, expr, cmd)
-				if err != nil {
-					return nil, errorRequest("wrong request: %s", cmd)
-				}
-				if temp != nil {
-					value = nil
-					switch temp.Type() {
-					case String:
-						key, err = temp.GetString()
-						if err != nil {
-							return nil, errorRequest("wrong type convert: %s", err.Error())
-						}
-						value = element.children[key]
-					case Numeric:
-						num, err = temp.getInteger()
-						if err == nil { // INTEGER
-							if num < 0 {
-								key = strconv.Itoa(element.Size() - num)
-							} else {
-								key = strconv.Itoa(num)
-							}
-						} else {
-							float, err = temp.GetNumeric()
-							if err != nil {
-								return nil, errorRequest("wrong type convert: %s", err.Error())
-							}
-							key = strconv.FormatFloat(float, 'g', -1, 64)
-						}
-						value = element.children[key]
-					case Bool:
-						ok, err = temp.GetBool()
-						if err != nil {
-							return nil, errorRequest("wrong type convert: %s", err.Error())
-						}
-						if ok {
-							temporary = append(temporary, element.Inheritors()...)
-						}
-						continue
-						// case Array: // get all keys from element via array values
-					}
-					if value != nil {
-						temporary = append(temporary, value)
-					}
-				}
-			}
-			result = temporary
-		default: // try to get by key & Union
-			if tokens.exists(",") {
-				keys = tokens.slice(",")
-				if len(keys) == 0 {
-					return nil, errorRequest("wrong request: %s", cmd)
-				}
-			} else {
-				keys = []string{cmd}
-			}
-
-			temporary = make([]*Node, 0)
-			for _, key = range keys { // fixme
-				for _, element := range result {
-					if element.IsArray() {
-						if key == "length" || key == "'length'" || key == "\"length\"" {
-							value, err = functions["length"](element)
-							if err != nil {
-								return
-							}
-							ok = true
-						} else if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
-							fkeys[0], err = getNumberIndex(element, key, math.NaN())
-							if err != nil {
-								return nil, err
-							}
-							if math.IsNaN(fkeys[0]) {
-								return nil, errorRequest("wrong request: %s", cmd)
-							}
-							if element.Size() == 0 {
-								ok = false
-							} else {
-								num = getPositiveIndex(int(fkeys[0]), element.Size())
-								key = strconv.Itoa(num)
-								value, ok = element.children[key]
-							}
-						} else {
-							key, _ = str(key)
-							num, err = strconv.Atoi(key)
-							if err != nil || element.Size() == 0 {
-								ok = false
-								err = nil
-							} else {
-								num = getPositiveIndex(num, element.Size())
-								key = strconv.Itoa(num)
-								value, ok = element.children[key]
-							}
-						}
-
-					} else if element.IsObject() {
-						key, _ = str(key)
-						value, ok = element.children[key]
-					}
-					if ok {
-						temporary = append(temporary, value)
-						ok = false
-					}
-				}
-			}
-			result = temporary
-		}
+	segments, err := compileSegments("", commands, nil)
+	if err != nil {
+		return nil, err
 	}
-	return
+	return evaluateSegments(node, segments)
 }
 
 // Eval evaluate expression `@.price == 19.95 && @.color == 'red'` to the result value i.e. Bool(true), Numeric(3.14), etc.
+//
+// Use CompileExpression to evaluate the same expression many times.
 func Eval(node *Node, cmd string) (result *Node, err error) {
-	calc, err := newBuffer([]byte(cmd)).rpn()
+	expr, err := CompileExpression(cmd)
 	if err != nil {
 		return nil, err
 	}
-	return eval(node, calc, cmd)
-}
-
-func eval(node *Node, expression rpn, cmd string) (result *Node, err error) {
-	var (
-		stack    = make([]*Node, 0)
-		slice    []*Node
-		temp     *Node
-		fn       Function
-		op       Operation
-		ok       bool
-		size     int
-		commands []string
-		bstr     []byte
-	)
-	for _, exp := range expression {
-		size = len(stack)
-		if fn, ok = functions[exp]; ok {
-			if size < 1 {
-				return nil, errorRequest("wrong request: %s", cmd)
-			}
-			stack[size-1], err = fn(stack[size-1])
-			if err != nil {
-				return
-			}
-		} else if op, ok = operations[exp]; ok {
-			if size < 2 {
-				return nil, errorRequest("wrong request: %s", cmd)
-			}
-			stack[size-2], err = op(stack[size-2], stack[size-1])
-			if err != nil {
-				return
-			}
-			stack = stack[:size-1]
-		} else if len(exp) > 0 {
-			if exp[0] == dollar || exp[0] == at {
-				commands, err = ParseJSONPath(exp)
-				if err != nil {
-					return
-				}
-				slice, err = deReference(node, commands)
-				if err != nil {
-					return
-				}
-				if len(slice) > 1 { // array given
-					stack = append(stack, ArrayNode("", slice))
-				} else if len(slice) == 1 {
-					stack = append(stack, slice[0])
-				} else { // no data found
-					// stack = append(stack, NullNode(""))
-					return NullNode(""), nil
-				}
-			} else if constant, ok := constants[strings.ToLower(exp)]; ok {
-				stack = append(stack, constant)
-			} else {
-				bstr = []byte(exp)
-				size = len(bstr)
-				if size >= 2 && bstr[0] == quote && bstr[size-1] == quote {
-					if sstr, ok := unquote(bstr, quote); ok {
-						temp = StringNode("", sstr)
-					} else {
-						err = errorRequest("wrong request: %s", cmd)
-					}
-				} else {
-					temp, err = Unmarshal(bstr)
-				}
-				if err != nil {
-					return
-				}
-				stack = append(stack, temp)
-			}
-		} else {
-			stack = append(stack, valueNode(nil, "", String, ""))
-		}
-	}
-	if len(stack) == 1 {
-		return stack[0], nil
-	}
-	if len(stack) == 0 {
-		return NullNode(""), nil
-	}
-	return nil, errorRequest("wrong request: %s", cmd)
-}
-
-func getNumberIndex(element *Node, input string, Default float64) (result float64, err error) {
-	var integer int
-	if input == "" {
-		result = Default
-	} else if input == "(@.length)" {
-		result = float64(element.Size())
-	} else if strings.HasPrefix(input, "(") && strings.HasSuffix(input, ")") {
-		var expr rpn
-		var temp *Node
-		expr, err = newBuffer([]byte(input[1 : len(input)-1])).rpn()
-		if err != nil {
-			return 0, err
-		}
-		temp, err = eval(element, expr, input)
-		if err != nil {
-			return
-		}
-		integer, err = temp.getInteger()
-		if err != nil {
-			return
-		}
-		result = float64(integer)
-	} else {
-		integer, err = strconv.Atoi(input)
-		if err != nil {
-			return 0, err
-		}
-		result = float64(integer)
-	}
-	return
+	return expr.Evaluate(node)
 }
 
 func getPositiveIndex(index int, count int) int {
@@ -0,0 +1,486 @@
+package ajson
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// kinds of the streamed JSONPath commands
+const (
+	streamNoop     = iota // `$` or `@`
+	streamDescent         // `..`
+	streamAny             // `*`
+	streamKeys            // keys, indexes and unions of them
+	streamSlice           // slices with non-negative bounds
+	streamFilter          // `?(...)` filters, calculated on each child
+	streamBoundary        // everything else: calculated on the materialized node
+)
+
+// streamCommand is the compiled JSONPath command, ready to be calculated on the stream
+type streamCommand struct {
+	kind    int
+	cmd     string
+	keys    []string
+	indexes []int
+	slice   [3]int
+	stop    bool
+	expr    *Expression
+}
+
+// streamFrame is the state of the container, which is being read at the moment
+type streamFrame struct {
+	states []int
+	node   *Node
+	array  bool
+	size   int
+	key    *string
+	own    *string
+	index  int
+	slot   int
+	match  bool
+	bounds []int
+	checks []int
+}
+
+// pathStream is the Handler, which calculates JSONPath on the Tokenizer events
+type pathStream struct {
+	commands []*streamCommand
+	segments []*segment
+	tails    [][]*segment
+	fn       func(*Node) error
+	frames   []*streamFrame
+	skip     int
+	builder  *builder
+	depth    int
+	results  [][]*Node
+}
+
+// JSONPathReader evaluates JSONPath on the JSON value, which is read from r, without loading the whole value in memory.
+// Function fn is called for each found node.
+//
+// Only nodes under the matching prefix of the path are materialized, all other data is skipped as soon as it is read.
+// Commands, which can't be calculated on the stream (e.g. negative indexes, script expressions or `length`),
+// are calculated on the materialized node, which was found by the previous commands. Filters are calculated on each
+// materialized child separately.
+//
+// Filters and scripts, which reference the root node `$`, can't be calculated on the stream and return an error.
+//
+// Found nodes are detached from the rest of the document, but keep their Path.
+// Nodes are returned in the order of the document.
+func JSONPathReader(r io.Reader, path string, fn func(*Node) error) error {
+	commands, offsets, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+	segments, err := compileSegments(path, commands, offsets)
+	if err != nil {
+		return err
+	}
+	stream := &pathStream{
+		commands: make([]*streamCommand, len(commands)),
+		segments: segments,
+		fn:       fn,
+	}
+	for i, cmd := range commands {
+		if stream.commands[i], err = newStreamCommand(cmd); err != nil {
+			return segments[i].error(nil, err)
+		}
+	}
+	// tails are the rest of the path, which is evaluated on the materialized node
+	current := &segment{kind: segmentCurrent, offset: -1, command: "@"}
+	stream.tails = make([][]*segment, len(commands)+1)
+	for i := range stream.tails {
+		stream.tails[i] = append([]*segment{current}, segments[i:]...)
+	}
+	err = NewTokenizer(r).Tokenize(stream)
+	if err == io.EOF {
+		return errorEOFAt(0)
+	}
+	return err
+}
+
+func newStreamCommand(cmd string) (result *streamCommand, err error) {
+	result = &streamCommand{kind: streamBoundary, cmd: cmd}
+	tokens, err := tokenize(cmd)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cmd == "$" || cmd == "@":
+		result.kind = streamNoop
+	case cmd == "..":
+		result.kind = streamDescent
+	case cmd == "*":
+		result.kind = streamAny
+	case tokens.exists(":"):
+		if tokens.count(":") > 3 {
+			return nil, errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
+		}
+		keys := tokens.slice(":")
+		if streamRoot(keys...) {
+			return nil, errorStream(cmd)
+		}
+		result.slice = [3]int{0, 0, 1}
+		for i, key := range keys {
+			if key == "" {
+				continue
+			}
+			if result.slice[i], err = strconv.Atoi(key); err != nil || result.slice[i] < 0 {
+				return result, nil
+			}
+			if i == 1 {
+				result.stop = true
+			}
+		}
+		if result.slice[2] > 0 {
+			result.kind = streamSlice
+		}
+	case strings.HasPrefix(cmd, "?(") && strings.HasSuffix(cmd, ")"):
+		if streamRoot(cmd[2 : len(cmd)-1]) {
+			return nil, errorStream(cmd)
+		}
+		result.expr, err = CompileExpression(cmd[2 : len(cmd)-1])
+		if err != nil {
+			return nil, err
+		}
+		result.kind = streamFilter
+	case strings.HasPrefix(cmd, "(") && strings.HasSuffix(cmd, ")"):
+		if streamRoot(cmd[1 : len(cmd)-1]) {
+			return nil, errorStream(cmd)
+		}
+	default:
+		keys := []string{cmd}
+		if tokens.exists(",") {
+			keys = tokens.slice(",")
+		}
+		for _, key := range keys {
+			if key == "length" || key == "'length'" || key == "\"length\"" || strings.HasPrefix(key, "(") {
+				if streamRoot(key) {
+					return nil, errorStream(cmd)
+				}
+				return result, nil
+			}
+			key, _ = str(key)
+			if index, err := strconv.Atoi(key); err == nil {
+				if index < 0 {
+					return result, nil
+				}
+				result.indexes = append(result.indexes, index)
+			}
+			result.keys = append(result.keys, key)
+		}
+		result.kind = streamKeys
+	}
+	return result, nil
+}
+
+// streamRoot checks if any of the expressions reference the root node
+func streamRoot(expressions ...string) bool {
+	for _, expression := range expressions {
+		tokens, err := tokenize(expression)
+		if err != nil {
+			return true
+		}
+		for _, token := range tokens {
+			if len(token) > 0 && (token[0] == dollar || token[0] == at) && strings.IndexByte(token, dollar) != -1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func errorStream(cmd string) error {
+	return errorRequest("command '%s' references the root node and can't be evaluated on the stream", cmd)
+}
+
+// BeginObject is the implementation of the Handler interface
+func (s *pathStream) BeginObject(offset int) error {
+	return s.begin(Object, offset)
+}
+
+// EndObject is the implementation of the Handler interface
+func (s *pathStream) EndObject(offset int) error {
+	return s.end(offset)
+}
+
+// BeginArray is the implementation of the Handler interface
+func (s *pathStream) BeginArray(offset int) error {
+	return s.begin(Array, offset)
+}
+
+// EndArray is the implementation of the Handler interface
+func (s *pathStream) EndArray(offset int) error {
+	return s.end(offset)
+}
+
+// Key is the implementation of the Handler interface
+func (s *pathStream) Key(key string, offset int) error {
+	if s.skip > 0 {
+		return nil
+	}
+	if s.builder != nil {
+		_ = s.builder.Key(key, offset)
+	}
+	s.frames[len(s.frames)-1].key = &key
+	return nil
+}
+
+// Value is the implementation of the Handler interface
+func (s *pathStream) Value(_type NodeType, source []byte, offset int) error {
+	if s.skip > 0 {
+		return nil
+	}
+	frame := s.frame(false)
+	if frame == nil {
+		return nil
+	}
+	if s.builder != nil {
+		_ = s.builder.Value(_type, source, offset)
+		s.link(frame, s.builder.last)
+	}
+	return s.complete(frame)
+}
+
+func (s *pathStream) begin(_type NodeType, offset int) error {
+	if s.skip > 0 {
+		s.skip++
+		return nil
+	}
+	frame := s.frame(true)
+	if frame == nil {
+		s.skip++
+		return nil
+	}
+	frame.array = _type == Array
+	if s.builder != nil {
+		if frame.array {
+			_ = s.builder.BeginArray(offset)
+		} else {
+			_ = s.builder.BeginObject(offset)
+		}
+		s.link(frame, s.builder.current)
+	}
+	s.frames = append(s.frames, frame)
+	return nil
+}
+
+func (s *pathStream) end(offset int) error {
+	if s.skip > 0 {
+		s.skip--
+		return nil
+	}
+	frame := s.frames[len(s.frames)-1]
+	if s.builder != nil {
+		if frame.array {
+			_ = s.builder.EndArray(offset)
+		} else {
+			_ = s.builder.EndObject(offset)
+		}
+	}
+	s.frames = s.frames[:len(s.frames)-1]
+	return s.complete(frame)
+}
+
+// frame creates the state of the new node, returns nil if node should be skipped
+func (s *pathStream) frame(container bool) (frame *streamFrame) {
+	frame = &streamFrame{slot: -1}
+	if len(s.frames) == 0 {
+		frame.states = s.closure(nil, 1)
+	} else {
+		parent := s.frames[len(s.frames)-1]
+		if parent.array {
+			frame.index = parent.size
+			parent.size++
+		} else {
+			frame.own = parent.key
+			parent.key = nil
+		}
+		for _, i := range parent.states {
+			if i >= len(s.commands) {
+				continue
+			}
+			command := s.commands[i]
+			switch command.kind {
+			case streamDescent:
+				if container {
+					frame.states = s.closure(frame.states, i)
+				}
+			case streamAny:
+				frame.states = s.closure(frame.states, i+1)
+			case streamKeys:
+				if command.matches(parent.array, frame.own, frame.index) {
+					frame.states = s.closure(frame.states, i+1)
+				}
+			case streamSlice:
+				if parent.array && command.contains(frame.index) {
+					frame.states = s.closure(frame.states, i+1)
+				}
+			case streamFilter:
+				frame.checks = append(frame.checks, i)
+			}
+		}
+	}
+	for _, i := range frame.states {
+		if i == len(s.commands) {
+			frame.match = true
+		} else if s.commands[i].kind == streamBoundary {
+			frame.bounds = append(frame.bounds, i)
+		}
+	}
+	if frame.match || len(frame.bounds) != 0 || len(frame.checks) != 0 {
+		frame.slot = len(s.results)
+		s.results = append(s.results, nil)
+	}
+	if s.builder == nil {
+		if frame.slot == -1 {
+			if len(frame.states) == 0 {
+				return nil
+			}
+			return frame
+		}
+		s.builder = new(builder)
+		s.depth = len(s.frames)
+	}
+	return frame
+}
+
+// closure adds the state with all states, which are reachable without reading the next node
+func (s *pathStream) closure(states []int, state int) []int {
+	for _, current := range states {
+		if current == state {
+			return states
+		}
+	}
+	states = append(states, state)
+	if state < len(s.commands) {
+		switch s.commands[state].kind {
+		case streamNoop, streamDescent:
+			states = s.closure(states, state+1)
+		}
+	}
+	return states
+}
+
+// link saves the node, created by builder, and attaches the root of materialized nodes to the skeleton of its parents
+func (s *pathStream) link(frame *streamFrame, node *Node) {
+	frame.node = node
+	if len(s.frames) != s.depth || len(s.frames) == 0 {
+		return
+	}
+	var parent *Node
+	for i, current := range s.frames {
+		skeleton := &Node{
+			_type:    Object,
+			children: make(map[string]*Node, 1),
+			dirty:    true,
+		}
+		if current.array {
+			skeleton._type = Array
+		}
+		if i != 0 {
+			skeleton.parent = parent
+			if parent.IsArray() {
+				index := current.index
+				skeleton.index = &index
+				parent.children[strconv.Itoa(index)] = skeleton
+			} else {
+				skeleton.key = current.own
+				parent.setChild(*current.own, skeleton)
+			}
+		}
+		parent = skeleton
+	}
+	node.parent = parent
+	if parent.IsArray() {
+		index := frame.index
+		node.index = &index
+		parent.children[strconv.Itoa(index)] = node
+	} else {
+		node.key = frame.own
+		parent.setChild(*frame.own, node)
+	}
+}
+
+// complete calculates results of the finished node, and sends them into the callback when the materialized part is done
+func (s *pathStream) complete(frame *streamFrame) (err error) {
+	if frame.slot != -1 {
+		var result, found []*Node
+		if frame.match {
+			result = append(result, frame.node)
+		}
+		for _, i := range frame.bounds {
+			found, err = evaluateSegments(frame.node, s.tails[i])
+			if err != nil {
+				return err
+			}
+			result = append(result, found...)
+		}
+		for _, i := range frame.checks {
+			value, err := s.commands[i].expr.Evaluate(frame.node)
+			if err != nil {
+				return s.segments[i].error(frame.node, err)
+			}
+			if value == nil {
+				continue
+			}
+			if ok, err := boolean(value); err != nil || !ok {
+				continue
+			}
+			found, err = evaluateSegments(frame.node, s.tails[i+1])
+			if err != nil {
+				return err
+			}
+			result = append(result, found...)
+		}
+		s.results[frame.slot] = result
+	}
+	if s.builder == nil || len(s.frames) != s.depth {
+		return nil
+	}
+	s.builder = nil
+	unique := make(map[*Node]bool)
+	for _, result := range s.results {
+		for _, node := range result {
+			if unique[node] {
+				continue
+			}
+			unique[node] = true
+			if err = s.fn(node); err != nil {
+				return err
+			}
+		}
+	}
+	s.results = s.results[:0]
+	return nil
+}
+
+// matches checks if the child of the container has one of the keys or indexes
+func (c *streamCommand) matches(array bool, key *string, index int) bool {
+	if array {
+		for _, current := range c.indexes {
+			if current == index {
+				return true
+			}
+		}
+		return false
+	}
+	if key == nil {
+		return false
+	}
+	for _, current := range c.keys {
+		if current == *key {
+			return true
+		}
+	}
+	return false
+}
+
+// contains checks if index is in the slice
+func (c *streamCommand) contains(index int) bool {
+	if index < c.slice[0] || (c.stop && index >= c.slice[1]) {
+		return false
+	}
+	return (index-c.slice[0])%c.slice[2] == 0
+}
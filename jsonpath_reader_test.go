@@ -0,0 +1,150 @@
+package ajson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestJSONPathReader(t *testing.T) {
+	tests := []string{
+		"$",
+		"@",
+		"$.store",
+		"$.store.book[*].author",
+		"$..author",
+		"$.store.*",
+		"$.store..price",
+		"$..book[2]",
+		"$..book[-1]",
+		"$..book[-1:]",
+		"$..book[0,1]",
+		"$..book[:2]",
+		"$..book[1:]",
+		"$..book[::2]",
+		"$..book[1:3:1]",
+		"$..book[?(@.isbn)]",
+		"$..book[?(@.price<10)].title",
+		"$..[?(@.price>10)]",
+		"$..*",
+		"$..book.length",
+		"$.store.book[(@.length-1)].title",
+		"$['store']['bicycle']['color']",
+		"$..['price','color']",
+		"$.store.book.*.price",
+		"$..unknown",
+		"$.store.book[10]",
+		"$[*]",
+	}
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			nodes, err := JSONPath(jsonExample, path)
+			if err != nil {
+				t.Fatalf("JSONPath() error = %v", err)
+			}
+			expected := Paths(nodes)
+			sort.Strings(expected)
+
+			result := make([]string, 0)
+			err = JSONPathReader(iotest.OneByteReader(bytes.NewReader(jsonExample)), path, func(node *Node) error {
+				result = append(result, node.Path())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("JSONPathReader() error = %v", err)
+			}
+			sort.Strings(result)
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("JSONPathReader() paths = %v, expected %v", result, expected)
+			}
+		})
+	}
+}
+
+func TestJSONPathReader_values(t *testing.T) {
+	result := make([]string, 0)
+	err := JSONPathReader(bytes.NewReader(jsonExample), "$..book[?(@.price < 10)]", func(node *Node) error {
+		result = append(result, node.MustKey("title").MustString())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("JSONPathReader() error = %v", err)
+	}
+	expected := []string{"Sayings of the Century", "Moby Dick"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("JSONPathReader() result = %v, expected %v", result, expected)
+	}
+}
+
+func TestJSONPathReader_error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  string
+	}{
+		{name: "root in filter", input: `[]`, path: "$[?(@.price < $.expensive)]"},
+		{name: "root in script", input: `[]`, path: "$[($.length - 1)]"},
+		{name: "root in slice", input: `[]`, path: "$[($.length - 1):]"},
+		{name: "wrong path", input: `[]`, path: "$[1"},
+		{name: "wrong JSON", input: `[1,]`, path: "$[*]"},
+		{name: "blank JSON", input: ``, path: "$"},
+		{name: "wrong slice", input: `[1, 2]`, path: "$[1:2:0]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := JSONPathReader(strings.NewReader(test.input), test.path, func(*Node) error {
+				return nil
+			})
+			if err == nil {
+				t.Errorf("JSONPathReader() expected error")
+			}
+		})
+	}
+}
+
+func TestJSONPathReader_syntax(t *testing.T) {
+	err := JSONPathReader(strings.NewReader("{\"a\": [1,\n 2 3]}"), "$..a", func(*Node) error {
+		return nil
+	})
+	expected := "wrong symbol '3' at 13 on line 2, column 4 in $['a']: expected ',' or ']'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("JSONPathReader() error = %v, expected %s", err, expected)
+	}
+}
+
+func TestJSONPathReader_callback(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := JSONPathReader(strings.NewReader(`[1, 2, 3, 4]`), "$[*]", func(*Node) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("JSONPathReader() expected callback error, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("JSONPathReader() wrong count of calls: %d", count)
+	}
+}
+
+func ExampleJSONPathReader() {
+	input := strings.NewReader(`{"items": [{"id": 1, "price": 10}, {"id": 2, "price": 25}, {"id": 3, "price": 5}]}`)
+	err := JSONPathReader(input, "$.items[?(@.price >= 10)].id", func(node *Node) error {
+		fmt.Printf("%s: %v\n", node.Path(), node.MustNumeric())
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// $['items'][0]['id']: 1
+	// $['items'][1]['id']: 2
+}
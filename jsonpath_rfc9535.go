@@ -0,0 +1,1181 @@
+package ajson
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Dialect is the syntax and semantic of JSONPath
+type Dialect int
+
+const (
+	// Goessner is the original JSONPath, described at http://goessner.net/articles/JsonPath/
+	Goessner Dialect = iota
+	// RFC9535 is JSONPath, standardized in RFC 9535: https://www.rfc-editor.org/rfc/rfc9535
+	RFC9535
+)
+
+// JSONPathOptions is the set of options for the JSONPath evaluation
+type JSONPathOptions struct {
+	Dialect Dialect
+}
+
+// JSONPathWithOptions returns slice of found elements in current JSON data, by it's JSONPath, evaluated with given options.
+//
+// With the RFC9535 dialect, the path is parsed and evaluated strictly by the RFC 9535:
+// filters are applied to the children of the current node with `?` selector, descendant segment `..` applies the next
+// selector to the node and all of its descendants, functions `length()`, `count()`, `match()`, `search()` and
+// `value()` are available in filters, and any syntax error or not well-typed expression returns *PathError.
+func JSONPathWithOptions(data []byte, path string, options JSONPathOptions) (result []*Node, err error) {
+	if options.Dialect == Goessner {
+		return JSONPath(data, path)
+	}
+	query, err := parseRFC9535(path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return query.evaluate(node, node).nodes, nil
+}
+
+// JSONPathWithOptions evaluate path for current node, with given options
+func (n *Node) JSONPathWithOptions(path string, options JSONPathOptions) (result []*Node, err error) {
+	if options.Dialect == Goessner {
+		return n.JSONPath(path)
+	}
+	query, err := parseRFC9535(path)
+	if err != nil {
+		return nil, err
+	}
+	root := n.root()
+	return query.evaluate(root, root).nodes, nil
+}
+
+// NormalizedPath returns the normalized path of the current Node, as described in RFC 9535, e.g.: `$['store']['book'][0]`
+func (n *Node) NormalizedPath() string {
+	if n.parent == nil {
+		return "$"
+	}
+	if n.key != nil {
+		return n.parent.NormalizedPath() + "['" + normalizedName(n.Key()) + "']"
+	}
+	return n.parent.NormalizedPath() + "[" + strconv.Itoa(n.Index()) + "]"
+}
+
+func normalizedName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch r {
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			if r < 0x20 {
+				sb.WriteString(`\u00`)
+				sb.WriteByte(hex[r>>4])
+				sb.WriteByte(hex[r&0xF])
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// region Types
+
+// rfcType is the type of expression in RFC 9535 type system
+type rfcType int
+
+const (
+	rfcValueType rfcType = iota
+	rfcLogicalType
+	rfcNodesType
+)
+
+// rfcResult is the result of the expression: value (nil means Nothing), logical or nodes
+type rfcResult struct {
+	value   *Node
+	logical bool
+	nodes   []*Node
+}
+
+// rfcExpression is a part of the filter expression
+type rfcExpression interface {
+	kind() rfcType
+	evaluate(root, current *Node) rfcResult
+}
+
+type rfcQuery struct {
+	relative bool
+	segments []*rfcSegment
+}
+
+type rfcSegment struct {
+	descendant bool
+	selectors  []*rfcSelector
+}
+
+const (
+	rfcName = iota
+	rfcWildcard
+	rfcIndex
+	rfcSlice
+	rfcFilter
+)
+
+type rfcSelector struct {
+	kind   int
+	name   string
+	index  int
+	slice  [3]*int
+	filter rfcExpression
+}
+
+type rfcLiteral struct {
+	value *Node
+}
+
+type rfcNot struct {
+	expression rfcExpression
+}
+
+type rfcAnd struct {
+	expressions []rfcExpression
+}
+
+type rfcOr struct {
+	expressions []rfcExpression
+}
+
+type rfcComparison struct {
+	operation   string
+	left, right rfcExpression
+}
+
+type rfcCall struct {
+	function  *rfcFunction
+	arguments []rfcExpression
+}
+
+type rfcFunction struct {
+	name   string
+	params []rfcType
+	result rfcType
+	fn     func(arguments []rfcResult) rfcResult
+}
+
+// endregion Types
+
+// region Functions
+
+var (
+	rfcFunctions = map[string]*rfcFunction{
+		"length": {
+			name:   "length",
+			params: []rfcType{rfcValueType},
+			result: rfcValueType,
+			fn: func(arguments []rfcResult) rfcResult {
+				value := arguments[0].value
+				if value == nil {
+					return rfcResult{}
+				}
+				switch value.Type() {
+				case String:
+					str, err := value.GetString()
+					if err != nil {
+						return rfcResult{}
+					}
+					return rfcResult{value: valueNode(nil, "length", Numeric, float64(utf8.RuneCountInString(str)))}
+				case Array, Object:
+					return rfcResult{value: valueNode(nil, "length", Numeric, float64(value.Size()))}
+				}
+				return rfcResult{}
+			},
+		},
+		"count": {
+			name:   "count",
+			params: []rfcType{rfcNodesType},
+			result: rfcValueType,
+			fn: func(arguments []rfcResult) rfcResult {
+				return rfcResult{value: valueNode(nil, "count", Numeric, float64(len(arguments[0].nodes)))}
+			},
+		},
+		"match": {
+			name:   "match",
+			params: []rfcType{rfcValueType, rfcValueType},
+			result: rfcLogicalType,
+			fn: func(arguments []rfcResult) rfcResult {
+				return rfcResult{logical: rfcRegexp(arguments[0].value, arguments[1].value, true)}
+			},
+		},
+		"search": {
+			name:   "search",
+			params: []rfcType{rfcValueType, rfcValueType},
+			result: rfcLogicalType,
+			fn: func(arguments []rfcResult) rfcResult {
+				return rfcResult{logical: rfcRegexp(arguments[0].value, arguments[1].value, false)}
+			},
+		},
+		"value": {
+			name:   "value",
+			params: []rfcType{rfcNodesType},
+			result: rfcValueType,
+			fn: func(arguments []rfcResult) rfcResult {
+				if len(arguments[0].nodes) == 1 {
+					return rfcResult{value: arguments[0].nodes[0]}
+				}
+				return rfcResult{}
+			},
+		},
+	}
+
+	rfcRegexpCache = struct {
+		sync.Mutex
+		compiled map[rfcRegexpKey]*regexp.Regexp
+	}{compiled: make(map[rfcRegexpKey]*regexp.Regexp)}
+)
+
+// rfcRegexpCacheSize limits the number of cached patterns, as they can come from the data
+const rfcRegexpCacheSize = 256
+
+// rfcRegexpKey is the key of the compiled pattern: match() and search() compile the same pattern differently
+type rfcRegexpKey struct {
+	expr string
+	full bool
+}
+
+// rfcRegexp matches the value with the I-Regexp (RFC 9485) pattern
+func rfcRegexp(value, pattern *Node, full bool) bool {
+	if value == nil || pattern == nil || !value.IsString() || !pattern.IsString() {
+		return false
+	}
+	str, err := value.GetString()
+	if err != nil {
+		return false
+	}
+	expr, err := pattern.GetString()
+	if err != nil {
+		return false
+	}
+	compiled := rfcCompile(expr, full)
+	if compiled == nil {
+		return false
+	}
+	return compiled.MatchString(str)
+}
+
+// rfcCompile returns the compiled I-Regexp pattern, or nil if the pattern is wrong
+func rfcCompile(expr string, full bool) *regexp.Regexp {
+	key := rfcRegexpKey{expr: expr, full: full}
+	rfcRegexpCache.Lock()
+	defer rfcRegexpCache.Unlock()
+	if compiled, ok := rfcRegexpCache.compiled[key]; ok {
+		return compiled
+	}
+	expr = iRegexp(expr)
+	if full {
+		expr = `^(?:` + expr + `)$`
+	}
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		compiled = nil
+	}
+	if len(rfcRegexpCache.compiled) >= rfcRegexpCacheSize {
+		rfcRegexpCache.compiled = make(map[rfcRegexpKey]*regexp.Regexp)
+	}
+	rfcRegexpCache.compiled[key] = compiled
+	return compiled
+}
+
+// iRegexp converts I-Regexp to the RE2 syntax: the dot doesn't match line terminators, `^` and `$` are ordinary
+// characters
+func iRegexp(expr string) string {
+	var (
+		sb      strings.Builder
+		escaped bool
+		class   bool
+	)
+	for _, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case class:
+			class = r != ']'
+		case r == '[':
+			class = true
+		case r == '.':
+			sb.WriteString(`[^\n\r]`)
+			continue
+		case r == '^' || r == '$':
+			// there are no anchors in I-Regexp
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// endregion Functions
+
+// region Parser
+
+type rfcParser struct {
+	data     string
+	index    int
+	depth    int // depth of the nested queries
+	position int // index of the segment of the root query, which is being parsed, the root identifier is 0
+	start    int // offset of the segment
+}
+
+// parseRFC9535 parses the JSONPath query by RFC 9535, errors are wrapped into PathError
+func parseRFC9535(path string) (query *rfcQuery, err error) {
+	parser := &rfcParser{data: path}
+	query, err = parser.query()
+	if err == nil && parser.index != len(parser.data) {
+		err = parser.error("unexpected symbol")
+	}
+	if err != nil {
+		return nil, parser.pathError(err)
+	}
+	return query, nil
+}
+
+// pathError wraps the cause into PathError of the current segment
+func (p *rfcParser) pathError(cause error) error {
+	end := p.index + 1
+	if end <= p.start {
+		end = p.start + 1
+	}
+	if end > len(p.data) {
+		end = len(p.data)
+	}
+	return &PathError{Path: p.data, Segment: p.position, Command: p.data[p.start:end], Offset: p.start, Err: cause}
+}
+
+func (p *rfcParser) error(message string) error {
+	if p.index < len(p.data) {
+		return errorRequest("RFC 9535: %s '%c' at %d", message, p.data[p.index], p.index)
+	}
+	return errorRequest("RFC 9535: %s at the end of the path", message)
+}
+
+func (p *rfcParser) current() byte {
+	if p.index < len(p.data) {
+		return p.data[p.index]
+	}
+	return 0
+}
+
+func (p *rfcParser) skip() {
+	for p.index < len(p.data) {
+		switch p.data[p.index] {
+		case skipS, skipT, skipN, skipR:
+			p.index++
+		default:
+			return
+		}
+	}
+}
+
+func (p *rfcParser) consume(value string) bool {
+	if strings.HasPrefix(p.data[p.index:], value) {
+		p.index += len(value)
+		return true
+	}
+	return false
+}
+
+func (p *rfcParser) query() (query *rfcQuery, err error) {
+	query = new(rfcQuery)
+	switch p.current() {
+	case dollar:
+	case at:
+		query.relative = true
+	default:
+		return nil, p.error("expected '$'")
+	}
+	p.index++
+	p.depth++
+	defer func() { p.depth-- }()
+	var segment *rfcSegment
+	for {
+		start := p.index
+		p.skip()
+		if p.depth == 1 {
+			p.position, p.start = len(query.segments)+1, p.index
+		}
+		if c := p.current(); c != dot && c != bracketL {
+			p.index = start
+			return query, nil
+		}
+		if segment, err = p.segment(); err != nil {
+			return nil, err
+		}
+		query.segments = append(query.segments, segment)
+	}
+}
+
+func (p *rfcParser) segment() (segment *rfcSegment, err error) {
+	segment = new(rfcSegment)
+	if p.consume("..") {
+		segment.descendant = true
+		if p.current() == bracketL {
+			segment.selectors, err = p.brackets()
+			return
+		}
+	} else if p.consume(".") {
+	} else {
+		segment.selectors, err = p.brackets()
+		return
+	}
+	if p.consume("*") {
+		segment.selectors = []*rfcSelector{{kind: rfcWildcard}}
+		return segment, nil
+	}
+	name, ok := p.shorthand()
+	if !ok {
+		return nil, p.error("expected member name")
+	}
+	segment.selectors = []*rfcSelector{{kind: rfcName, name: name}}
+	return segment, nil
+}
+
+func (p *rfcParser) shorthand() (string, bool) {
+	start := p.index
+	for p.index < len(p.data) {
+		r, size := utf8.DecodeRuneInString(p.data[p.index:])
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= 0x80 && r != utf8.RuneError) || (r >= '0' && r <= '9' && p.index != start) {
+			p.index += size
+			continue
+		}
+		break
+	}
+	return p.data[start:p.index], start != p.index
+}
+
+func (p *rfcParser) brackets() (selectors []*rfcSelector, err error) {
+	if !p.consume("[") {
+		return nil, p.error("expected '['")
+	}
+	var selector *rfcSelector
+	for {
+		p.skip()
+		if selector, err = p.selector(); err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skip()
+		if p.consume("]") {
+			return selectors, nil
+		}
+		if !p.consume(",") {
+			return nil, p.error("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *rfcParser) selector() (selector *rfcSelector, err error) {
+	selector = new(rfcSelector)
+	switch c := p.current(); {
+	case c == quote || c == quotes:
+		selector.kind = rfcName
+		selector.name, err = p.string()
+	case c == asterisk:
+		p.index++
+		selector.kind = rfcWildcard
+	case c == question:
+		p.index++
+		p.skip()
+		selector.kind = rfcFilter
+		if selector.filter, err = p.or(); err != nil {
+			return nil, err
+		}
+		selector.filter, err = p.logical(selector.filter)
+	default:
+		var value [3]*int
+		for i := 0; i < 3; i++ {
+			if c = p.current(); c == minus || (c >= '0' && c <= '9') {
+				var num int
+				if num, err = p.integer(); err != nil {
+					return nil, err
+				}
+				value[i] = &num
+				p.skip()
+			}
+			if i == 2 || !p.consume(":") {
+				break
+			}
+			selector.kind = rfcSlice
+			p.skip()
+		}
+		if selector.kind == rfcSlice {
+			selector.slice = value
+		} else if value[0] != nil {
+			selector.kind = rfcIndex
+			selector.index = *value[0]
+		} else {
+			return nil, p.error("expected selector")
+		}
+	}
+	return selector, err
+}
+
+func (p *rfcParser) integer() (int, error) {
+	start := p.index
+	p.consume("-")
+	digits := p.index
+	for p.index < len(p.data) && p.data[p.index] >= '0' && p.data[p.index] <= '9' {
+		p.index++
+	}
+	text := p.data[start:p.index]
+	if digits == p.index || (p.data[digits] == '0' && (p.index-digits > 1 || digits != start)) {
+		p.index = start
+		return 0, p.error("wrong integer")
+	}
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || value > 1<<53-1 || value < -(1<<53-1) {
+		p.index = start
+		return 0, p.error("integer is out of range")
+	}
+	return int(value), nil
+}
+
+func (p *rfcParser) string() (string, error) {
+	var (
+		sb     strings.Builder
+		search = p.data[p.index]
+	)
+	p.index++
+	for p.index < len(p.data) {
+		c := p.data[p.index]
+		switch {
+		case c == search:
+			p.index++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.error("wrong symbol in string")
+		case c == backslash:
+			p.index++
+			switch p.current() {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '/', backslash, search:
+				sb.WriteByte(p.current())
+			case 'u':
+				r, ok := p.unicode()
+				if !ok {
+					return "", p.error("wrong unicode escape")
+				}
+				sb.WriteRune(r)
+				continue
+			default:
+				return "", p.error("wrong escape")
+			}
+			p.index++
+		default:
+			sb.WriteByte(c)
+			p.index++
+		}
+	}
+	return "", p.error("unterminated string")
+}
+
+// unicode parses `uXXXX` escape sequence, with surrogate pairs
+func (p *rfcParser) unicode() (rune, bool) {
+	r := p.hex4()
+	if r < 0 {
+		return 0, false
+	}
+	if r >= 0xDC00 && r <= 0xDFFF {
+		return 0, false
+	}
+	if r >= 0xD800 && r <= 0xDBFF {
+		if !p.consume(`\`) {
+			return 0, false
+		}
+		low := p.hex4()
+		if low < 0xDC00 || low > 0xDFFF {
+			return 0, false
+		}
+		r = (r-0xD800)<<10 + (low - 0xDC00) + 0x10000
+	}
+	return r, true
+}
+
+func (p *rfcParser) hex4() rune {
+	if !p.consume("u") || p.index+4 > len(p.data) {
+		return -1
+	}
+	value, err := strconv.ParseUint(p.data[p.index:p.index+4], 16, 32)
+	if err != nil {
+		return -1
+	}
+	p.index += 4
+	return rune(value)
+}
+
+// logical converts the expression to the LogicalType, if possible
+func (p *rfcParser) logical(expression rfcExpression) (rfcExpression, error) {
+	switch value := expression.(type) {
+	case *rfcQuery:
+		return value, nil
+	case *rfcLiteral:
+		return nil, p.error("literal is not a logical expression")
+	case *rfcCall:
+		if value.function.result == rfcValueType {
+			return nil, p.error("function '" + value.function.name + "' doesn't return a logical value")
+		}
+	}
+	return expression, nil
+}
+
+// comparable checks if the expression could be compared
+func (p *rfcParser) comparable(expression rfcExpression) (rfcExpression, error) {
+	switch value := expression.(type) {
+	case *rfcLiteral:
+		return value, nil
+	case *rfcQuery:
+		if !value.singular() {
+			return nil, p.error("query is not singular")
+		}
+		return value, nil
+	case *rfcCall:
+		if value.function.result == rfcValueType {
+			return value, nil
+		}
+	}
+	return nil, p.error("expression is not comparable")
+}
+
+func (p *rfcParser) or() (expression rfcExpression, err error) {
+	if expression, err = p.and(); err != nil {
+		return nil, err
+	}
+	result := &rfcOr{}
+	for {
+		start := p.index
+		p.skip()
+		if !p.consume("||") {
+			p.index = start
+			break
+		}
+		p.skip()
+		if len(result.expressions) == 0 {
+			if expression, err = p.logical(expression); err != nil {
+				return nil, err
+			}
+			result.expressions = append(result.expressions, expression)
+		}
+		if expression, err = p.and(); err != nil {
+			return nil, err
+		}
+		if expression, err = p.logical(expression); err != nil {
+			return nil, err
+		}
+		result.expressions = append(result.expressions, expression)
+	}
+	if len(result.expressions) == 0 {
+		return expression, nil
+	}
+	return result, nil
+}
+
+func (p *rfcParser) and() (expression rfcExpression, err error) {
+	if expression, err = p.basic(); err != nil {
+		return nil, err
+	}
+	result := &rfcAnd{}
+	for {
+		start := p.index
+		p.skip()
+		if !p.consume("&&") {
+			p.index = start
+			break
+		}
+		p.skip()
+		if len(result.expressions) == 0 {
+			if expression, err = p.logical(expression); err != nil {
+				return nil, err
+			}
+			result.expressions = append(result.expressions, expression)
+		}
+		if expression, err = p.basic(); err != nil {
+			return nil, err
+		}
+		if expression, err = p.logical(expression); err != nil {
+			return nil, err
+		}
+		result.expressions = append(result.expressions, expression)
+	}
+	if len(result.expressions) == 0 {
+		return expression, nil
+	}
+	return result, nil
+}
+
+func (p *rfcParser) basic() (expression rfcExpression, err error) {
+	if p.consume("!") {
+		p.skip()
+		if p.current() == parenthesesL {
+			expression, err = p.parentheses()
+		} else {
+			expression, err = p.primary()
+			if _, ok := expression.(*rfcLiteral); ok {
+				return nil, p.error("literal can't be negated")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if expression, err = p.logical(expression); err != nil {
+			return nil, err
+		}
+		return &rfcNot{expression: expression}, nil
+	}
+	if p.current() == parenthesesL {
+		return p.parentheses()
+	}
+	if expression, err = p.primary(); err != nil {
+		return nil, err
+	}
+	start := p.index
+	p.skip()
+	for _, operation := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(operation) {
+			comparison := &rfcComparison{operation: operation}
+			if comparison.left, err = p.comparable(expression); err != nil {
+				return nil, err
+			}
+			p.skip()
+			if expression, err = p.primary(); err != nil {
+				return nil, err
+			}
+			if comparison.right, err = p.comparable(expression); err != nil {
+				return nil, err
+			}
+			return comparison, nil
+		}
+	}
+	p.index = start
+	return expression, nil
+}
+
+func (p *rfcParser) parentheses() (expression rfcExpression, err error) {
+	p.index++
+	p.skip()
+	if expression, err = p.or(); err != nil {
+		return nil, err
+	}
+	if expression, err = p.logical(expression); err != nil {
+		return nil, err
+	}
+	p.skip()
+	if !p.consume(")") {
+		return nil, p.error("expected ')'")
+	}
+	return expression, nil
+}
+
+// primary parses query, function call or literal
+func (p *rfcParser) primary() (rfcExpression, error) {
+	c := p.current()
+	switch {
+	case c == dollar || c == at:
+		return p.query()
+	case c == quote || c == quotes:
+		value, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return &rfcLiteral{value: valueNode(nil, "", String, value)}, nil
+	case c == minus || (c >= '0' && c <= '9'):
+		return p.number()
+	case c >= 'a' && c <= 'z':
+		start := p.index
+		for p.index < len(p.data) {
+			c = p.data[p.index]
+			if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_') {
+				break
+			}
+			p.index++
+		}
+		name := p.data[start:p.index]
+		if p.current() == parenthesesL {
+			return p.call(name, start)
+		}
+		switch name {
+		case "true":
+			return &rfcLiteral{value: valueNode(nil, "", Bool, true)}, nil
+		case "false":
+			return &rfcLiteral{value: valueNode(nil, "", Bool, false)}, nil
+		case "null":
+			return &rfcLiteral{value: valueNode(nil, "", Null, nil)}, nil
+		}
+		p.index = start
+	}
+	return nil, p.error("expected expression")
+}
+
+func (p *rfcParser) number() (rfcExpression, error) {
+	start := p.index
+	p.consume("-")
+	digits := p.index
+	for p.index < len(p.data) && p.data[p.index] >= '0' && p.data[p.index] <= '9' {
+		p.index++
+	}
+	if digits == p.index || (p.data[digits] == '0' && p.index-digits > 1) {
+		p.index = start
+		return nil, p.error("wrong number")
+	}
+	if p.consume(".") {
+		fraction := p.index
+		for p.index < len(p.data) && p.data[p.index] >= '0' && p.data[p.index] <= '9' {
+			p.index++
+		}
+		if fraction == p.index {
+			return nil, p.error("wrong number")
+		}
+	}
+	if c := p.current(); c == 'e' || c == 'E' {
+		p.index++
+		if c = p.current(); c == minus || c == plus {
+			p.index++
+		}
+		exponent := p.index
+		for p.index < len(p.data) && p.data[p.index] >= '0' && p.data[p.index] <= '9' {
+			p.index++
+		}
+		if exponent == p.index {
+			return nil, p.error("wrong number")
+		}
+	}
+	value, err := strconv.ParseFloat(p.data[start:p.index], 64)
+	if err != nil {
+		p.index = start
+		return nil, p.error("wrong number")
+	}
+	return &rfcLiteral{value: valueNode(nil, "", Numeric, value)}, nil
+}
+
+func (p *rfcParser) call(name string, start int) (expression rfcExpression, err error) {
+	function, ok := rfcFunctions[name]
+	if !ok {
+		p.index = start
+		return nil, p.error("unknown function")
+	}
+	call := &rfcCall{function: function}
+	p.index++
+	p.skip()
+	for !p.consume(")") {
+		if len(call.arguments) != 0 {
+			if !p.consume(",") {
+				return nil, p.error("expected ',' or ')'")
+			}
+			p.skip()
+		}
+		if len(call.arguments) == len(function.params) {
+			return nil, p.error("too many arguments of function '" + name + "'")
+		}
+		if expression, err = p.or(); err != nil {
+			return nil, err
+		}
+		if expression, err = p.argument(function.params[len(call.arguments)], expression); err != nil {
+			return nil, err
+		}
+		call.arguments = append(call.arguments, expression)
+		p.skip()
+	}
+	if len(call.arguments) != len(function.params) {
+		return nil, p.error("not enough arguments of function '" + name + "'")
+	}
+	return call, nil
+}
+
+// argument checks if the expression is well-typed for the parameter of the function
+func (p *rfcParser) argument(param rfcType, expression rfcExpression) (rfcExpression, error) {
+	switch param {
+	case rfcValueType:
+		return p.comparable(expression)
+	case rfcLogicalType:
+		return p.logical(expression)
+	}
+	switch value := expression.(type) {
+	case *rfcQuery:
+		return value, nil
+	case *rfcCall:
+		if value.function.result == rfcNodesType {
+			return value, nil
+		}
+	}
+	return nil, p.error("argument is not a nodes type")
+}
+
+// endregion Parser
+
+// region Evaluation
+
+// singular checks if the query returns no more than one node
+func (q *rfcQuery) singular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		if kind := segment.selectors[0].kind; kind != rfcName && kind != rfcIndex {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *rfcQuery) kind() rfcType {
+	return rfcNodesType
+}
+
+func (q *rfcQuery) evaluate(root, current *Node) rfcResult {
+	nodes := []*Node{root}
+	if q.relative {
+		nodes[0] = current
+	}
+	for _, segment := range q.segments {
+		nodes = segment.apply(root, nodes)
+	}
+	if len(nodes) == 1 {
+		return rfcResult{nodes: nodes, value: nodes[0], logical: true}
+	}
+	return rfcResult{nodes: nodes, logical: len(nodes) != 0}
+}
+
+func (s *rfcSegment) apply(root *Node, nodes []*Node) (result []*Node) {
+	result = make([]*Node, 0)
+	for _, node := range nodes {
+		if s.descendant {
+			for _, descendant := range rfcDescendants(node, nil) {
+				for _, selector := range s.selectors {
+					result = selector.apply(root, descendant, result)
+				}
+			}
+		} else {
+			for _, selector := range s.selectors {
+				result = selector.apply(root, node, result)
+			}
+		}
+	}
+	return result
+}
+
+// rfcDescendants returns the node and all of its descendants in the document order
+func rfcDescendants(node *Node, result []*Node) []*Node {
+	result = append(result, node)
+	for _, child := range node.Inheritors() {
+		result = rfcDescendants(child, result)
+	}
+	return result
+}
+
+func (s *rfcSelector) apply(root, node *Node, result []*Node) []*Node {
+	switch s.kind {
+	case rfcName:
+		if node.IsObject() {
+			if child, ok := node.children[s.name]; ok {
+				result = append(result, child)
+			}
+		}
+	case rfcWildcard:
+		result = append(result, node.Inheritors()...)
+	case rfcIndex:
+		if node.IsArray() {
+			index := s.index
+			if index < 0 {
+				index += node.Size()
+			}
+			if child, ok := node.children[strconv.Itoa(index)]; ok && index >= 0 {
+				result = append(result, child)
+			}
+		}
+	case rfcSlice:
+		if node.IsArray() {
+			for _, index := range rfcSliceIndexes(s.slice, node.Size()) {
+				result = append(result, node.children[strconv.Itoa(index)])
+			}
+		}
+	case rfcFilter:
+		for _, child := range node.Inheritors() {
+			if s.filter.evaluate(root, child).logical {
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// rfcSliceIndexes calculates indexes of the array slice, by RFC 9535 section 2.3.4.2.2
+func rfcSliceIndexes(slice [3]*int, length int) (result []int) {
+	step := 1
+	if slice[2] != nil {
+		step = *slice[2]
+	}
+	if step == 0 {
+		return nil
+	}
+	normalize := func(value *int, Default int) int {
+		if value == nil {
+			return Default
+		}
+		if *value >= 0 {
+			return *value
+		}
+		return length + *value
+	}
+	bound := func(value, lower, upper int) int {
+		return int(math.Min(math.Max(float64(value), float64(lower)), float64(upper)))
+	}
+	if step > 0 {
+		lower := bound(normalize(slice[0], 0), 0, length)
+		upper := bound(normalize(slice[1], length), 0, length)
+		for i := lower; i < upper; i += step {
+			result = append(result, i)
+		}
+	} else {
+		upper := bound(normalize(slice[0], length-1), -1, length-1)
+		lower := bound(normalize(slice[1], -length-1), -1, length-1)
+		for i := upper; lower < i; i += step {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (l *rfcLiteral) kind() rfcType {
+	return rfcValueType
+}
+
+func (l *rfcLiteral) evaluate(_, _ *Node) rfcResult {
+	return rfcResult{value: l.value}
+}
+
+func (n *rfcNot) kind() rfcType {
+	return rfcLogicalType
+}
+
+func (n *rfcNot) evaluate(root, current *Node) rfcResult {
+	return rfcResult{logical: !n.expression.evaluate(root, current).logical}
+}
+
+func (a *rfcAnd) kind() rfcType {
+	return rfcLogicalType
+}
+
+func (a *rfcAnd) evaluate(root, current *Node) rfcResult {
+	for _, expression := range a.expressions {
+		if !expression.evaluate(root, current).logical {
+			return rfcResult{logical: false}
+		}
+	}
+	return rfcResult{logical: true}
+}
+
+func (o *rfcOr) kind() rfcType {
+	return rfcLogicalType
+}
+
+func (o *rfcOr) evaluate(root, current *Node) rfcResult {
+	for _, expression := range o.expressions {
+		if expression.evaluate(root, current).logical {
+			return rfcResult{logical: true}
+		}
+	}
+	return rfcResult{logical: false}
+}
+
+func (c *rfcComparison) kind() rfcType {
+	return rfcLogicalType
+}
+
+func (c *rfcComparison) evaluate(root, current *Node) rfcResult {
+	left := c.left.evaluate(root, current).value
+	right := c.right.evaluate(root, current).value
+	var result bool
+	switch c.operation {
+	case "==":
+		result = rfcEqual(left, right)
+	case "!=":
+		result = !rfcEqual(left, right)
+	case "<":
+		result = rfcLess(left, right)
+	case "<=":
+		result = rfcLess(left, right) || rfcEqual(left, right)
+	case ">":
+		result = rfcLess(right, left)
+	case ">=":
+		result = rfcLess(right, left) || rfcEqual(left, right)
+	}
+	return rfcResult{logical: result}
+}
+
+func (c *rfcCall) kind() rfcType {
+	return c.function.result
+}
+
+func (c *rfcCall) evaluate(root, current *Node) rfcResult {
+	arguments := make([]rfcResult, len(c.arguments))
+	for i, argument := range c.arguments {
+		arguments[i] = argument.evaluate(root, current)
+	}
+	result := c.function.fn(arguments)
+	if c.function.result == rfcNodesType {
+		result.logical = len(result.nodes) != 0
+	}
+	return result
+}
+
+// rfcEqual compares values, by RFC 9535 section 2.3.5.2.2, nil means Nothing
+func rfcEqual(left, right *Node) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	result, err := left.Eq(right)
+	return err == nil && result
+}
+
+// rfcLess compares values, by RFC 9535 section 2.3.5.2.2, nil means Nothing
+func rfcLess(left, right *Node) bool {
+	if left == nil || right == nil || left.Type() != right.Type() {
+		return false
+	}
+	if left.IsNumeric() || left.IsString() {
+		result, err := left.Le(right)
+		return err == nil && result
+	}
+	return false
+}
+
+// endregion Evaluation
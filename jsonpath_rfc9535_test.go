@@ -0,0 +1,347 @@
+package ajson
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var rfc9535Options = JSONPathOptions{Dialect: RFC9535}
+
+func TestJSONPathWithOptions_RFC9535(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		expected []string
+	}{
+		// RFC 9535 section 1.5
+		{name: "authors", input: string(jsonExample), path: `$.store.book[*].author`, expected: []string{
+			`$['store']['book'][0]['author']`,
+			`$['store']['book'][1]['author']`,
+			`$['store']['book'][2]['author']`,
+			`$['store']['book'][3]['author']`,
+		}},
+		{name: "all authors", input: string(jsonExample), path: `$..author`, expected: []string{
+			`$['store']['book'][0]['author']`,
+			`$['store']['book'][1]['author']`,
+			`$['store']['book'][2]['author']`,
+			`$['store']['book'][3]['author']`,
+		}},
+		{name: "store", input: string(jsonExample), path: `$.store.*`, expected: []string{
+			`$['store']['bicycle']`,
+			`$['store']['book']`,
+		}},
+		{name: "third book", input: string(jsonExample), path: `$..book[2]`, expected: []string{
+			`$['store']['book'][2]`,
+		}},
+		{name: "last book", input: string(jsonExample), path: `$..book[-1]`, expected: []string{
+			`$['store']['book'][3]`,
+		}},
+		{name: "first two books", input: string(jsonExample), path: `$..book[0,1]`, expected: []string{
+			`$['store']['book'][0]`,
+			`$['store']['book'][1]`,
+		}},
+		{name: "books with isbn", input: string(jsonExample), path: `$..book[?@.isbn]`, expected: []string{
+			`$['store']['book'][2]`,
+			`$['store']['book'][3]`,
+		}},
+		{name: "cheap books", input: string(jsonExample), path: `$..book[?@.price<10]`, expected: []string{
+			`$['store']['book'][0]`,
+			`$['store']['book'][2]`,
+		}},
+		{name: "root in filter", input: string(jsonExample), path: `$..book[?(@.price > $.store.book[0].price)].title`, expected: []string{
+			`$['store']['book'][1]['title']`,
+			`$['store']['book'][2]['title']`,
+			`$['store']['book'][3]['title']`,
+		}},
+
+		// RFC 9535 section 2.3
+		{name: "name", input: `{"o": {"j j": {"k.k": 3}}, "'": {"@": 2}}`, path: `$.o['j j']['k.k']`, expected: []string{`$['o']['j j']['k.k']`}},
+		{name: "name double quoted", input: `{"o": {"j j": {"k.k": 3}}, "'": {"@": 2}}`, path: `$["o"]["j j"]["k.k"]`, expected: []string{`$['o']['j j']['k.k']`}},
+		{name: "name escaped", input: `{"o": {"j j": {"k.k": 3}}, "'": {"@": 2}}`, path: `$["'"]["@"]`, expected: []string{`$['\'']['@']`}},
+		{name: "name unicode", input: `{"☺": 1}`, path: `$["☺"]`, expected: []string{`$['☺']`}},
+		{name: "name shorthand unicode", input: `{"☺": 1}`, path: `$.☺`, expected: []string{`$['☺']`}},
+		{name: "name on array", input: `[1]`, path: `$.a`, expected: []string{}},
+		{name: "wildcard", input: `{"o": {"j": 1, "k": 2}, "a": [5, 3]}`, path: `$.a[*]`, expected: []string{`$['a'][0]`, `$['a'][1]`}},
+		{name: "wildcard on scalar", input: `{"o": 1}`, path: `$.o[*]`, expected: []string{}},
+		{name: "index", input: `["a", "b"]`, path: `$[1]`, expected: []string{`$[1]`}},
+		{name: "index negative", input: `["a", "b"]`, path: `$[-2]`, expected: []string{`$[0]`}},
+		{name: "index out of range", input: `["a", "b"]`, path: `$[-3]`, expected: []string{}},
+		{name: "index on object", input: `{"0": 1}`, path: `$[0]`, expected: []string{}},
+		{name: "duplicates", input: `["a", "b"]`, path: `$[0, 0]`, expected: []string{`$[0]`, `$[0]`}},
+		{name: "slice", input: `["a", "b", "c", "d", "e", "f", "g"]`, path: `$[1:3]`, expected: []string{`$[1]`, `$[2]`}},
+		{name: "slice start", input: `["a", "b", "c", "d", "e", "f", "g"]`, path: `$[5:]`, expected: []string{`$[5]`, `$[6]`}},
+		{name: "slice step", input: `["a", "b", "c", "d", "e", "f", "g"]`, path: `$[1:5:2]`, expected: []string{`$[1]`, `$[3]`}},
+		{name: "slice negative step", input: `["a", "b", "c", "d", "e", "f", "g"]`, path: `$[5:1:-2]`, expected: []string{`$[5]`, `$[3]`}},
+		{name: "slice reverse", input: `["a", "b", "c", "d"]`, path: `$[::-1]`, expected: []string{`$[3]`, `$[2]`, `$[1]`, `$[0]`}},
+		{name: "slice zero step", input: `["a", "b", "c", "d"]`, path: `$[::0]`, expected: []string{}},
+		{name: "slice spaces", input: `["a", "b", "c", "d"]`, path: `$[ 1 : 2 : 1 ]`, expected: []string{`$[1]`}},
+		{name: "slice on object", input: `{"a": 1}`, path: `$[:]`, expected: []string{}},
+
+		// RFC 9535 section 2.3.5.3
+		{name: "filter equal", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?@.b == 'kilo']`, expected: []string{`$['a'][9]`}},
+		{name: "filter parentheses", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?(@.b == 'kilo')]`, expected: []string{`$['a'][9]`}},
+		{name: "filter greater", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?@>3.5]`, expected: []string{`$['a'][1]`, `$['a'][4]`, `$['a'][5]`}},
+		{name: "filter exists", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?@.b]`, expected: []string{`$['a'][6]`, `$['a'][7]`, `$['a'][8]`, `$['a'][9]`}},
+		{name: "filter on object", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}], "o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}}`, path: `$.o[?@<3, ?@<3]`, expected: []string{`$['o']['p']`, `$['o']['q']`, `$['o']['p']`, `$['o']['q']`}},
+		{name: "filter or", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?@<2 || @.b == "k"]`, expected: []string{`$['a'][2]`, `$['a'][7]`}},
+		{name: "filter and", input: `[{"a": 1, "b": 2}, {"a": 1}, {"b": 2}]`, path: `$[?@.a && @.b]`, expected: []string{`$[0]`}},
+		{name: "filter not", input: `[{"a": 1, "b": 2}, {"a": 1}, {"b": 2}]`, path: `$[?!@.a]`, expected: []string{`$[2]`}},
+		{name: "filter not parentheses", input: `[{"a": 1, "b": 2}, {"a": 1}, {"b": 2}]`, path: `$[?!(@.a && @.b)]`, expected: []string{`$[1]`, `$[2]`}},
+		{name: "filter match", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?match(@.b, "[jk]")]`, expected: []string{`$['a'][6]`, `$['a'][7]`}},
+		{name: "filter search", input: `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}]}`, path: `$.a[?search(@.b, "[jk]")]`, expected: []string{`$['a'][6]`, `$['a'][7]`, `$['a'][9]`}},
+		{name: "filter nested", input: `{"o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}}`, path: `$.o[?@>1 && @<4]`, expected: []string{`$['o']['q']`, `$['o']['r']`}},
+		{name: "filter nothing equal", input: `[{"a": 1}, {"b": 1}]`, path: `$[?@.x == @.y]`, expected: []string{`$[0]`, `$[1]`}},
+		{name: "filter nothing less", input: `[{"a": 1}, {"b": 1}]`, path: `$[?@.x <= @.y]`, expected: []string{`$[0]`, `$[1]`}},
+		{name: "filter deep equal", input: `[{"a": [1, {"b": null}]}, {"a": [1, {"b": false}]}]`, path: `$[?@.a == $[0].a]`, expected: []string{`$[0]`}},
+		{name: "filter different types", input: `[1, "1", true, null]`, path: `$[?@ == 1]`, expected: []string{`$[0]`}},
+		{name: "filter string less", input: `["a", "b", 1]`, path: `$[?@ < "b"]`, expected: []string{`$[0]`}},
+		{name: "filter integer and float", input: `[1, 1.0, 1e0, 2]`, path: `$[?@ == 1.0]`, expected: []string{`$[0]`, `$[1]`, `$[2]`}},
+
+		// RFC 9535 section 2.4
+		{name: "length string", input: `["ab", "☺☺☺", 1]`, path: `$[?length(@) == 3]`, expected: []string{`$[1]`}},
+		{name: "length array", input: `[[1, 2], {"a": 1, "b": 2}, "ab"]`, path: `$[?length(@) == 2]`, expected: []string{`$[0]`, `$[1]`, `$[2]`}},
+		{name: "length nothing", input: `[{"a": "x"}, {"a": 1}]`, path: `$[?length(@.a) == length(@.b)]`, expected: []string{`$[1]`}},
+		{name: "count", input: `[{"a": [1, 2]}, {"a": [1]}]`, path: `$[?count(@.a[*]) > 1]`, expected: []string{`$[0]`}},
+		{name: "count descendant", input: `[{"a": {"b": {"c": 1}}}, {"a": 1}]`, path: `$[?count(@..*) > 2]`, expected: []string{`$[0]`}},
+		{name: "match full", input: `["2024-01-01", "x2024-01-01", "2024-01-01x"]`, path: `$[?match(@, "[0-9]{4}-[0-9]{2}-[0-9]{2}")]`, expected: []string{`$[0]`}},
+		{name: "match dot", input: `["a", "\n", "\r"]`, path: `$[?match(@, ".")]`, expected: []string{`$[0]`}},
+		{name: "search anchors", input: `["ab", "^ab$", "x^ab$x"]`, path: `$[?search(@, "^ab$")]`, expected: []string{`$[1]`, `$[2]`}},
+		{name: "match wrong regexp", input: `["a"]`, path: `$[?match(@, "(")]`, expected: []string{}},
+		{name: "match not string", input: `[1]`, path: `$[?match(@, "1")]`, expected: []string{}},
+		{name: "value", input: `[{"a": [1]}, {"a": [1, 1]}]`, path: `$[?value(@.a[*]) == 1]`, expected: []string{`$[0]`}},
+		{name: "value descendant", input: `[{"c": 1}, {"a": {"c": 1}, "b": {"c": 1}}]`, path: `$[?value(@..c) == 1]`, expected: []string{`$[0]`}},
+
+		// RFC 9535 section 2.5.2
+		{name: "descendant", input: `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`, path: `$..j`, expected: []string{`$['a'][2][0]['j']`, `$['o']['j']`}},
+		{name: "descendant index", input: `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`, path: `$..[0]`, expected: []string{`$['a'][0]`, `$['a'][2][0]`}},
+		{name: "descendant wildcard", input: `{"a": [5, [3]]}`, path: `$..*`, expected: []string{`$['a']`, `$['a'][0]`, `$['a'][1]`, `$['a'][1][0]`}},
+		{name: "descendant brackets", input: `{"a": [5, [3]]}`, path: `$..[*]`, expected: []string{`$['a']`, `$['a'][0]`, `$['a'][1]`, `$['a'][1][0]`}},
+		{name: "descendant on scalar", input: `1`, path: `$..*`, expected: []string{}},
+
+		// RFC 9535 section 2.6
+		{name: "null", input: `{"a": null, "b": [null], "c": [{}], "null": 1}`, path: `$.a`, expected: []string{`$['a']`}},
+		{name: "null index", input: `{"a": null, "b": [null], "c": [{}], "null": 1}`, path: `$.a[0]`, expected: []string{}},
+		{name: "null name", input: `{"a": null, "b": [null], "c": [{}], "null": 1}`, path: `$.null`, expected: []string{`$['null']`}},
+		{name: "null filter", input: `{"a": null, "b": [null], "c": [{}], "null": 1}`, path: `$[?@ == null]`, expected: []string{`$['a']`}},
+
+		{name: "root", input: `{"k": "v"}`, path: `$`, expected: []string{`$`}},
+		{name: "spaces between segments", input: `{"a": {"b": 1}}`, path: "$ .a\n\t['b']", expected: []string{`$['a']['b']`}},
+		{name: "normalized escape", input: `{"a\u0001\n'\\": 1}`, path: `$.*`, expected: []string{`$['a\u0001\n\'\\']`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := JSONPathWithOptions([]byte(test.input), test.path, rfc9535Options)
+			if err != nil {
+				t.Fatalf("JSONPathWithOptions() error = %v", err)
+			}
+			result := make([]string, 0, len(nodes))
+			for _, node := range nodes {
+				result = append(result, node.NormalizedPath())
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("JSONPathWithOptions() = %v, expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestJSONPathWithOptions_RFC9535_error(t *testing.T) {
+	tests := []string{
+		``,
+		` $`,
+		`$ `,
+		`a`,
+		`$.`,
+		`$..`,
+		`$[`,
+		`$[]`,
+		`$[1`,
+		`$[1,]`,
+		`$['a]`,
+		`$['\a']`,
+		"$['\u0001']",
+		`$["\uD800"]`,
+		`$. a`,
+		`$.1`,
+		`$[01]`,
+		`$[-0]`,
+		`$[1.0]`,
+		`$[9007199254740992]`,
+		`$[1:2:3:4]`,
+		`$[(@.length-1)]`,
+		`$[?(@.a)`,
+		`$[?]`,
+		`$[?1]`,
+		`$[?'a']`,
+		`$[?true]`,
+		`$[?@.a == ]`,
+		`$[?@.* == 1]`,
+		`$[?@..a == 1]`,
+		`$[?@[0, 1] == 1]`,
+		`$[?@.a == 1 == 2]`,
+		`$[?!@.a == 1]`,
+		`$[?@.a = 1]`,
+		`$[?@.a === 1]`,
+		`$[?foo(@)]`,
+		`$[?length(@)]`,
+		`$[?length(@.*) == 1]`,
+		`$[?length(@, @) == 1]`,
+		`$[?count(1) == 1]`,
+		`$[?count(@.a) == count()]`,
+		`$[?match(@.a, "a") == true]`,
+		`$[?value(@.a)]`,
+		`$[?@.a == 01]`,
+		`$[?@.a == 1.]`,
+		`$[?@.a == 1e]`,
+		`$[?@.a == True]`,
+		`$[?(@.a]`,
+	}
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			_, err := JSONPathWithOptions([]byte(`{}`), path, rfc9535Options)
+			if err == nil {
+				t.Errorf("JSONPathWithOptions() expected error")
+			} else if cause, ok := rootCause(err).(Error); !ok || cause.Type != WrongRequest {
+				t.Errorf("JSONPathWithOptions() wrong error: %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONPathWithOptions_RFC9535_regexp(t *testing.T) {
+	input := []byte(`["ab", "xab", "abx", "^ab", "x^ab$"]`)
+	paths := []struct {
+		path     string
+		expected int
+	}{
+		{path: `$[?match(@, 'ab')]`, expected: 1},
+		{path: `$[?search(@, '^ab')]`, expected: 2},
+		{path: `$[?match(@, 'ab')]`, expected: 1},
+		{path: `$[?search(@, 'ab')]`, expected: 5},
+		{path: `$[?match(@, '^ab')]`, expected: 1},
+		{path: `$[?match(@, 'x^ab[$]')]`, expected: 1},
+		{path: `$[?match(@, '[^x]ab')]`, expected: 1},
+	}
+	for _, test := range paths {
+		nodes, err := JSONPathWithOptions(input, test.path, rfc9535Options)
+		if err != nil {
+			t.Fatalf("JSONPathWithOptions(%s) error = %v", test.path, err)
+		}
+		if len(nodes) != test.expected {
+			t.Errorf("JSONPathWithOptions(%s) = %d nodes, expected %d", test.path, len(nodes), test.expected)
+		}
+	}
+}
+
+func TestRFCCompile_limit(t *testing.T) {
+	for i := 0; i < rfcRegexpCacheSize*2; i++ {
+		if rfcCompile(strconv.Itoa(i), i%2 == 0) == nil {
+			t.Fatalf("rfcCompile(%d) returns nil", i)
+		}
+	}
+	rfcRegexpCache.Lock()
+	defer rfcRegexpCache.Unlock()
+	if size := len(rfcRegexpCache.compiled); size > rfcRegexpCacheSize {
+		t.Errorf("rfcRegexpCache size = %d, expected at most %d", size, rfcRegexpCacheSize)
+	}
+}
+
+func TestJSONPathWithOptions_RFC9535_pathError(t *testing.T) {
+	tests := []struct {
+		path    string
+		segment int
+		command string
+		offset  int
+	}{
+		{path: `a`, segment: 0, command: `a`, offset: 0},
+		{path: `$.a[?@.b == ]`, segment: 2, command: `[?@.b == ]`, offset: 3},
+		{path: `$.a[?@.b[1:2:3:4] == 1]`, segment: 2, command: `[?@.b[1:2:3:`, offset: 3},
+		{path: `$..[1:x]`, segment: 1, command: `..[1:x`, offset: 1},
+		{path: `$.a x`, segment: 2, command: `x`, offset: 4},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			_, err := JSONPathWithOptions([]byte(`{}`), test.path, rfc9535Options)
+			result, ok := err.(*PathError)
+			if !ok {
+				t.Fatalf("JSONPathWithOptions() error = %v, expected PathError", err)
+			}
+			if result.Path != test.path || result.Segment != test.segment || result.Command != test.command || result.Offset != test.offset {
+				t.Errorf("JSONPathWithOptions() error = %#v", result)
+			}
+			if cause, ok := result.Unwrap().(Error); !ok || cause.Type != WrongRequest {
+				t.Errorf("JSONPathWithOptions() error = %v, expected to wrap Error", err)
+			}
+		})
+	}
+}
+
+func TestJSONPathWithOptions_Goessner(t *testing.T) {
+	nodes, err := JSONPathWithOptions(jsonExample, "$..book.length", JSONPathOptions{})
+	if err != nil {
+		t.Fatalf("JSONPathWithOptions() error = %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].MustNumeric() != 4 {
+		t.Errorf("JSONPathWithOptions() wrong result: %v", nodes)
+	}
+	nodes, err = JSONPathWithOptions(jsonExample, "$..book.length", rfc9535Options)
+	if err != nil {
+		t.Fatalf("JSONPathWithOptions() error = %v", err)
+	}
+	if len(nodes) != 0 {
+		t.Errorf("JSONPathWithOptions() wrong result: %v", nodes)
+	}
+}
+
+func TestNode_JSONPathWithOptions(t *testing.T) {
+	root := Must(Unmarshal(jsonExample))
+	book := root.MustKey("store").MustKey("book")
+	nodes, err := book.JSONPathWithOptions(`$.store.bicycle.color`, rfc9535Options)
+	if err != nil {
+		t.Fatalf("JSONPathWithOptions() error = %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].MustString() != "red" {
+		t.Errorf("JSONPathWithOptions() wrong result: %v", nodes)
+	}
+	if _, err = book.JSONPathWithOptions(`$[?(@.a]`, rfc9535Options); err == nil {
+		t.Errorf("JSONPathWithOptions() expected error")
+	}
+}
+
+func TestNode_NormalizedPath(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": [{"b\tc": 1}], "'": {"\\": 2}}`)))
+	tests := []struct {
+		node     *Node
+		expected string
+	}{
+		{node: root, expected: `$`},
+		{node: root.MustKey("a"), expected: `$['a']`},
+		{node: root.MustKey("a").MustIndex(0), expected: `$['a'][0]`},
+		{node: root.MustKey("a").MustIndex(0).MustKey("b\tc"), expected: `$['a'][0]['b\tc']`},
+		{node: root.MustKey("'").MustKey(`\`), expected: `$['\'']['\\']`},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			if result := test.node.NormalizedPath(); result != test.expected {
+				t.Errorf("NormalizedPath() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func ExampleJSONPathWithOptions() {
+	json := []byte(`{"users": [{"name": "Alice", "email": "alice@example.com"}, {"name": "Bob"}, {"name": "Carol", "email": "carol@example.com"}]}`)
+	nodes, err := JSONPathWithOptions(json, `$.users[?match(@.email, ".*\\.com")].name`, JSONPathOptions{Dialect: RFC9535})
+	if err != nil {
+		panic(err)
+	}
+	for _, node := range nodes {
+		fmt.Printf("%s: %s\n", node.NormalizedPath(), node.MustString())
+	}
+	// Output:
+	// $['users'][0]['name']: Alice
+	// $['users'][2]['name']: Carol
+}
@@ -0,0 +1,71 @@
+package ajson
+
+import (
+	"database/sql/driver"
+)
+
+// MarshalJSON implements the json.Marshaler interface, so the *Node can be used as a field in the structures for
+// the encoding/json package. The result is the same as Marshal returns.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	return Marshal(n)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface: the node is replaced with the parsed data, keeping its place
+// in the tree. Data is copied, as UnmarshalSafe does.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	root, err := UnmarshalSafe(data)
+	if err != nil {
+		return err
+	}
+	return n.assign(root)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The result is the same as Marshal returns.
+func (n *Node) MarshalText() ([]byte, error) {
+	return Marshal(n)
+}
+
+// Scan implements the sql.Scanner interface, so the node can be read from the JSON or JSONB column.
+// SQL NULL becomes the Null node.
+func (n *Node) Scan(src interface{}) error {
+	var (
+		root *Node
+		err  error
+	)
+	switch value := src.(type) {
+	case nil:
+		root, err = Unmarshal([]byte("null"))
+	case []byte:
+		root, err = UnmarshalSafe(value)
+	case string:
+		root, err = Unmarshal([]byte(value))
+	default:
+		return errorRequest("can't scan %T into Node", src)
+	}
+	if err != nil {
+		return err
+	}
+	return n.assign(root)
+}
+
+// Valuer returns the driver.Valuer, which stores the node as JSON text, e.g. into the JSON or JSONB column:
+//
+//	_, err := db.Exec("INSERT INTO documents (body) VALUES ($1)", node.Valuer())
+//
+// Node itself can't be used as a query argument, because Node.Value returns the Go value of the node.
+func (n *Node) Valuer() driver.Valuer {
+	return nodeValuer{node: n}
+}
+
+// nodeValuer stores the node as JSON text
+type nodeValuer struct {
+	node *Node
+}
+
+// Value implements the driver.Valuer interface. Nil node is stored as SQL NULL.
+func (v nodeValuer) Value() (driver.Value, error) {
+	if v.node == nil {
+		return nil, nil
+	}
+	return Marshal(v.node)
+}
@@ -0,0 +1,171 @@
+package ajson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+var (
+	_ json.Marshaler         = (*Node)(nil)
+	_ json.Unmarshaler       = (*Node)(nil)
+	_ encoding.TextMarshaler = (*Node)(nil)
+	_ sql.Scanner            = (*Node)(nil)
+	_ driver.Valuer          = (*Node)(nil).Valuer()
+)
+
+func TestNode_MarshalJSON(t *testing.T) {
+	type Document struct {
+		ID    int   `json:"id"`
+		Body  *Node `json:"body"`
+		Empty *Node `json:"empty"`
+		Value Node  `json:"value"`
+	}
+	body := Must(Unmarshal([]byte(`{"b": [1.50, "x"], "a": null}`)))
+	if err := body.AppendObject("c", Int64Node("", 9007199254740993)); err != nil {
+		t.Fatalf("AppendObject() error = %v", err)
+	}
+	document := Document{ID: 1, Body: body, Value: *Must(Unmarshal([]byte(`true`)))}
+	result, err := json.Marshal(&document)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	expected := `{"id":1,"body":{"b":[1.50,"x"],"a":null,"c":9007199254740993},"empty":null,"value":true}`
+	if string(result) != expected {
+		t.Errorf("json.Marshal() = %s, expected %s", result, expected)
+	}
+
+	var decoded Document
+	if err = json.Unmarshal(result, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.ID != 1 || decoded.Empty != nil || !decoded.Value.MustBool() {
+		t.Errorf("json.Unmarshal() = %+v", decoded)
+	}
+	if value := decoded.Body.MustKey("c").MustInt64(); value != 9007199254740993 {
+		t.Errorf("json.Unmarshal() = %d", value)
+	}
+	if source := string(decoded.Body.Source()); source != `{"b":[1.50,"x"],"a":null,"c":9007199254740993}` {
+		t.Errorf("json.Unmarshal() source = %s", source)
+	}
+	// data should be copied
+	copy(result, `{"id":2,"body":{"b":[9.99`)
+	if value := decoded.Body.MustKey("b").MustIndex(0).MustNumeric(); value != 1.5 {
+		t.Errorf("json.Unmarshal() data wasn't copied: %v", value)
+	}
+	if err = json.Unmarshal([]byte(`{"body": {"a": }}`), &decoded); err == nil {
+		t.Errorf("json.Unmarshal() expected error")
+	}
+}
+
+func TestNode_UnmarshalJSON(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": {"b": 1}, "c": 2}`)))
+	if err := root.MustKey("a").UnmarshalJSON([]byte(`[1, 2]`)); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if result := marshalString(root); result != `{"a":[1, 2],"c":2}` {
+		t.Errorf("UnmarshalJSON() = %s", result)
+	}
+	if path := root.MustKey("a").MustIndex(1).Path(); path != "$['a'][1]" {
+		t.Errorf("UnmarshalJSON() wrong path = %s", path)
+	}
+	if err := root.MustKey("c").UnmarshalJSON([]byte(`{`)); err == nil {
+		t.Errorf("UnmarshalJSON() expected error")
+	}
+}
+
+func TestNode_MarshalText(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": "b"}`)))
+	result, err := root.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+	if string(result) != `{"a": "b"}` {
+		t.Errorf("MarshalText() = %s", result)
+	}
+	if _, err = (&Node{}).MarshalText(); err == nil {
+		t.Errorf("MarshalText() expected error")
+	}
+}
+
+func TestNode_Scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      interface{}
+		expected string
+		err      bool
+	}{
+		{name: "bytes", src: []byte(`{"a": [1, 2]}`), expected: `{"a": [1, 2]}`},
+		{name: "string", src: `"text"`, expected: `"text"`},
+		{name: "NULL", src: nil, expected: `null`},
+		{name: "number", src: int64(1), err: true},
+		{name: "wrong", src: []byte(`{"a"}`), err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := new(Node)
+			err := node.Scan(test.src)
+			if test.err {
+				if err == nil {
+					t.Errorf("Scan() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if result := marshalString(node); result != test.expected {
+				t.Errorf("Scan() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+
+	src := []byte(`[1]`)
+	node := new(Node)
+	if err := node.Scan(src); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	src[1] = '2'
+	if value := node.MustIndex(0).MustNumeric(); value != 1 {
+		t.Errorf("Scan() data wasn't copied: %v", value)
+	}
+}
+
+func TestNode_Valuer(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": 1}`)))
+	_ = root.AppendObject("b", StringNode("", "c"))
+	value, err := root.Valuer().Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if result, ok := value.([]byte); !ok || string(result) != `{"a":1,"b":"c"}` {
+		t.Errorf("Value() = %v", value)
+	}
+	if !driver.IsValue(value) {
+		t.Errorf("Value() isn't valid driver.Value: %T", value)
+	}
+	if value, err = (*Node)(nil).Valuer().Value(); err != nil || value != nil {
+		t.Errorf("Value() = %v, %v, expected nil", value, err)
+	}
+}
+
+func ExampleNode_MarshalJSON() {
+	type Event struct {
+		Name    string `json:"name"`
+		Payload *Node  `json:"payload"`
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(`{"name": "order", "payload": {"id": 9007199254740993, "price": 1.50}}`), &event); err != nil {
+		panic(err)
+	}
+	fmt.Println(event.Payload.MustKey("id").MustInt64())
+	_ = event.Payload.AppendObject("paid", BoolNode("", true))
+	result, _ := json.Marshal(event)
+	fmt.Printf("%s\n", result)
+	// Output:
+	// 9007199254740993
+	// {"name":"order","payload":{"id":9007199254740993,"price":1.50,"paid":true}}
+}
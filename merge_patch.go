@@ -0,0 +1,112 @@
+package ajson
+
+import (
+	"sort"
+)
+
+// MergePatch applies JSON Merge Patch (RFC 7386) to the target node and returns it.
+//
+// Target is changed in place: only the nodes, set by the patch, are replaced, so Marshal will return
+// all untouched parts of the target byte-identical to the source. Target could be nil, in this case
+// the new node will be created.
+// Values of the patch are copied, so the patch node can be reused.
+//
+// Example:
+//
+//	target: {"a": "b", "c": {"d": "e", "f": "g"}}
+//	patch:  {"a": "z", "c": {"f": null}}
+//	result: {"a": "z", "c": {"d": "e"}}
+func MergePatch(target, patch *Node) (*Node, error) {
+	if patch == nil {
+		return nil, errorRequest("patch should not be nil")
+	}
+	if target == nil {
+		target = NullNode("")
+	}
+	if err := mergePatch(target, patch); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// CreateMergePatch returns JSON Merge Patch (RFC 7386), which transforms the original node into the modified one.
+// Please note, that keys with the null value can't be set by the merge patch: null is used to delete the key.
+func CreateMergePatch(original, modified *Node) (*Node, error) {
+	if original == nil || modified == nil {
+		return nil, errorRequest("nodes should not be nil")
+	}
+	if !original.IsObject() || !modified.IsObject() {
+		return modified.Clone(), nil
+	}
+	keys := make([]string, 0, len(original.children)+len(modified.children))
+	for key := range original.children {
+		keys = append(keys, key)
+	}
+	for key := range modified.children {
+		if _, ok := original.children[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	result := make(map[string]*Node)
+	for _, key := range keys {
+		left, lok := original.children[key]
+		right, rok := modified.children[key]
+		switch {
+		case !rok:
+			result[key] = NullNode("")
+		case !lok:
+			result[key] = right.Clone()
+		case left.IsObject() && right.IsObject():
+			value, err := CreateMergePatch(left, right)
+			if err != nil {
+				return nil, err
+			}
+			if len(value.children) != 0 {
+				result[key] = value
+			}
+		default:
+			ok, err := left.Eq(right)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				result[key] = right.Clone()
+			}
+		}
+	}
+	return ObjectNode("", result), nil
+}
+
+func mergePatch(target, patch *Node) error {
+	if !patch.IsObject() {
+		return target.assign(patch.Clone())
+	}
+	if !target.IsObject() {
+		if err := target.assign(ObjectNode("", nil)); err != nil {
+			return err
+		}
+	}
+	for _, key := range patch.Keys() {
+		value := patch.children[key]
+		child, ok := target.children[key]
+		if value.IsNull() {
+			if ok {
+				if err := target.remove(child); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !ok {
+			child = NullNode("")
+			if err := target.AppendObject(key, child); err != nil {
+				return err
+			}
+		}
+		if err := mergePatch(child, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
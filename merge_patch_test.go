@@ -0,0 +1,174 @@
+package ajson
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		// RFC 7386 appendix A
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, expected: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, expected: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expected: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, expected: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expected: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, expected: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, expected: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, expected: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, expected: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, expected: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, expected: `{"e":null,"a":1}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, expected: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, expected: `{"a":{"bb":{}}}`},
+		// other cases
+		{target: `{"a":{"b":1}}`, patch: `{}`, expected: `{"a":{"b":1}}`},
+		{target: `{"a":1}`, patch: `{"b":null}`, expected: `{"a":1}`},
+		{target: `{"id":1234567890123456789}`, patch: `{"id":1234567890123456788}`, expected: `{"id":1234567890123456788}`},
+	}
+	for _, test := range tests {
+		t.Run(test.target+" + "+test.patch, func(t *testing.T) {
+			target := Must(Unmarshal([]byte(test.target)))
+			result, err := MergePatch(target, Must(Unmarshal([]byte(test.patch))))
+			if err != nil {
+				t.Fatalf("MergePatch() error = %v", err)
+			}
+			if result != target {
+				t.Errorf("MergePatch() should change the target in place")
+			}
+			expected := Must(Unmarshal([]byte(test.expected)))
+			if ok, err := result.Eq(expected); err != nil || !ok {
+				t.Errorf("MergePatch() = %s, expected %s", marshalString(result), test.expected)
+			}
+		})
+	}
+}
+
+func TestMergePatch_source(t *testing.T) {
+	input := `{
+		"id": 1,
+		"name": "Alice",
+		"address": {"city": "Paris", "zip": "75001"},
+		"tags": [ "a" , "b" ]
+	}`
+	target := Must(Unmarshal([]byte(input)))
+	address := target.MustKey("address")
+	tags := target.MustKey("tags")
+	if _, err := MergePatch(target, Must(Unmarshal([]byte(`{}`)))); err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	if result := marshalString(target); result != input {
+		t.Errorf("MergePatch() without changes = %s, expected %s", result, input)
+	}
+	if _, err := MergePatch(target, Must(Unmarshal([]byte(`{"id":2}`)))); err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	if target.MustKey("address") != address || target.MustKey("tags") != tags {
+		t.Errorf("MergePatch() should keep untouched nodes")
+	}
+	if string(address.Source()) != `{"city": "Paris", "zip": "75001"}` || string(tags.Source()) != `[ "a" , "b" ]` {
+		t.Errorf("MergePatch() untouched nodes was changed: %s, %s", address.Source(), tags.Source())
+	}
+	if result := marshalString(target); !strings.Contains(result, `{"city": "Paris", "zip": "75001"}`) || !strings.Contains(result, `[ "a" , "b" ]`) {
+		t.Errorf("MergePatch() untouched nodes should be marshaled verbatim: %s", result)
+	}
+	if target.MustKey("id").MustNumeric() != 2 {
+		t.Errorf("MergePatch() id = %s, expected 2", marshalString(target.MustKey("id")))
+	}
+}
+
+func TestMergePatch_bigInteger(t *testing.T) {
+	target := Must(Unmarshal([]byte(`{"id":1234567890123456789}`)))
+	if _, err := MergePatch(target, Must(Unmarshal([]byte(`{"id":1234567890123456788}`)))); err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	if result := marshalString(target); result != `{"id":1234567890123456788}` {
+		t.Errorf("MergePatch() = %s, expected {\"id\":1234567890123456788}", result)
+	}
+}
+
+func TestMergePatch_order(t *testing.T) {
+	target := Must(Unmarshal([]byte(`{"z":1,"m":{"y":1}}`)))
+	result, err := MergePatch(target, Must(Unmarshal([]byte(`{"b":1,"m":{"d":2,"c":3},"a":2}`))))
+	if err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	expected := `{"z":1,"m":{"y":1,"d":2,"c":3},"b":1,"a":2}`
+	if value := marshalString(result); value != expected {
+		t.Errorf("MergePatch() = %s, expected %s", value, expected)
+	}
+}
+
+func TestMergePatch_nil(t *testing.T) {
+	result, err := MergePatch(nil, Must(Unmarshal([]byte(`{"a":{"b":null}}`))))
+	if err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+	if value := marshalString(result); value != `{"a":{}}` {
+		t.Errorf("MergePatch() = %s, expected {\"a\":{}}", value)
+	}
+	if _, err = MergePatch(NullNode(""), nil); err == nil {
+		t.Errorf("MergePatch() expected error")
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+		expected string
+	}{
+		{original: `{"a":"b"}`, modified: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{original: `{"a":"b"}`, modified: `{"a":"b","b":"c"}`, expected: `{"b":"c"}`},
+		{original: `{"a":"b","b":"c"}`, modified: `{"b":"c"}`, expected: `{"a":null}`},
+		{original: `{"a":{"b":"c","d":"e"}}`, modified: `{"a":{"b":"d","d":"e"}}`, expected: `{"a":{"b":"d"}}`},
+		{original: `{"a":{"b":"c"}}`, modified: `{"a":{"b":"c"}}`, expected: `{}`},
+		{original: `{"a":[1,2]}`, modified: `{"a":[1]}`, expected: `{"a":[1]}`},
+		{original: `{"a":{"b":1}}`, modified: `{"a":1}`, expected: `{"a":1}`},
+		{original: `[1]`, modified: `{"a":1}`, expected: `{"a":1}`},
+		{original: `{"a":1}`, modified: `"a"`, expected: `"a"`},
+		{original: `{"id":1234567890123456789}`, modified: `{"id":1234567890123456788}`, expected: `{"id":1234567890123456788}`},
+	}
+	for _, test := range tests {
+		t.Run(test.original+" -> "+test.modified, func(t *testing.T) {
+			original := Must(Unmarshal([]byte(test.original)))
+			modified := Must(Unmarshal([]byte(test.modified)))
+			patch, err := CreateMergePatch(original, modified)
+			if err != nil {
+				t.Fatalf("CreateMergePatch() error = %v", err)
+			}
+			expected := Must(Unmarshal([]byte(test.expected)))
+			if ok, err := patch.Eq(expected); err != nil || !ok {
+				t.Errorf("CreateMergePatch() = %s, expected %s", marshalString(patch), test.expected)
+			}
+			result, err := MergePatch(original, patch)
+			if err != nil {
+				t.Fatalf("MergePatch() error = %v", err)
+			}
+			if ok, err := result.Eq(modified); err != nil || !ok {
+				t.Errorf("MergePatch(CreateMergePatch()) = %s, expected %s", marshalString(result), test.modified)
+			}
+		})
+	}
+}
+
+func ExampleMergePatch() {
+	target := Must(Unmarshal([]byte(`{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}}`)))
+	patch := Must(Unmarshal([]byte(`{"title": "Hello!", "author": {"familyName": null}}`)))
+	result, err := MergePatch(target, patch)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", result.MustKey("title").MustString())
+	fmt.Printf("%s\n", result.MustKey("author").Keys())
+	// Output:
+	// Hello!
+	// [givenName]
+}
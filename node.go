@@ -25,6 +25,7 @@ import (
 type Node struct {
 	parent   *Node
 	children map[string]*Node
+	keys     []string // keys of an Object, in order of parsing or appending
 	key      *string
 	index    *int
 	_type    NodeType
@@ -32,6 +33,7 @@ type Node struct {
 	borders  [2]int
 	value    atomic.Value
 	dirty    bool
+	trivia   *trivia // layout of the node with the comments, if it was parsed with the KeepComments option
 }
 
 // NodeType is a kind of reflection of JSON type to a type of golang
@@ -134,9 +136,11 @@ func ObjectNode(key string, value map[string]*Node) (current *Node) {
 	}
 	if value != nil {
 		current.value.Store(value)
+		current.keys = sortedKeys(value)
 		for key, val := range value {
+			name := key
 			val.parent = current
-			val.key = &key
+			val.key = &name
 		}
 	} else {
 		current.children = make(map[string]*Node)
@@ -165,7 +169,7 @@ func newNode(parent *Node, buf *buffer, _type NodeType, key **string) (current *
 			if *key == nil {
 				err = errorSymbol(buf)
 			} else {
-				parent.children[**key] = current
+				parent.setChild(**key, current)
 				*key = nil
 			}
 		} else {
@@ -235,13 +239,40 @@ func (n *Node) Size() int {
 	return len(n.children)
 }
 
-// Keys will return count all keys of children of current node, please check, that parent of this node has an Object type
+// Keys will return all keys of children of current node, in order of parsing or appending, please check, that current node has an Object type
 func (n *Node) Keys() (result []string) {
-	result = make([]string, 0, len(n.children))
-	for key := range n.children {
+	result = make([]string, len(n.keys))
+	copy(result, n.keys)
+	return
+}
+
+// setChild sets the child of an Object by the key, new keys are added to the end
+func (n *Node) setChild(key string, child *Node) {
+	if _, ok := n.children[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.children[key] = child
+}
+
+// dropChild removes the child of an Object by the key
+func (n *Node) dropChild(key string) {
+	delete(n.children, key)
+	for i, value := range n.keys {
+		if value == key {
+			n.keys = append(n.keys[:i:i], n.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// sortedKeys returns sorted keys of the map
+func sortedKeys(value map[string]*Node) []string {
+	result := make([]string, 0, len(value))
+	for key := range value {
 		result = append(result, key)
 	}
-	return
+	sort.Strings(result)
+	return result
 }
 
 // IsArray returns true if current node is Array
@@ -620,11 +651,10 @@ func (n *Node) Eq(node *Node) (result bool, err error) {
 			}
 			result = lnum == rnum
 		case Numeric:
-			lnum, rnum, err := _floats(n, node)
+			result, err = equalNumbers(n, node)
 			if err != nil {
 				return false, err
 			}
-			result = lnum == rnum
 		case String:
 			lnum, rnum, err := _strings(n, node)
 			if err != nil {
@@ -831,11 +861,11 @@ func (n *Node) Inheritors() (result []*Node) {
 
 // JSONPath evaluate path for current node
 func (n *Node) JSONPath(path string) (result []*Node, err error) {
-	commands, err := ParseJSONPath(path)
+	compiled, err := CompileJSONPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return deReference(n, commands)
+	return compiled.Evaluate(n)
 }
 
 // root returns the root node
@@ -134,11 +134,26 @@ func (n *Node) clone() *Node {
 		_type:    n._type,
 		data:     n.data,
 		borders:  n.borders,
-		value:    n.value,
 		dirty:    n.dirty,
+		trivia:   n.trivia,
+	}
+	// cached value of the container refers to the original children, so it will be calculated again
+	if value := n.value.Load(); value != nil && !n.isContainer() {
+		node.value.Store(value)
+	}
+	if n.keys != nil {
+		node.keys = make([]string, len(n.keys))
+		copy(node.keys, n.keys)
 	}
 	for key, value := range n.children {
-		node.children[key] = value.clone()
+		child := value.clone()
+		child.parent = node
+		if value.trivia != nil {
+			layout := *value.trivia
+			layout.parent = node
+			child.trivia = &layout
+		}
+		node.children[key] = child
 	}
 	return node
 }
@@ -169,8 +184,9 @@ func (n *Node) update(_type NodeType, value interface{}) error {
 		case Object:
 			nodes := value.(map[string]*Node)
 			n.children = make(map[string]*Node, len(nodes))
-			for key, node := range nodes {
-				if err = n.appendNode(&key, node); err != nil {
+			for _, key := range sortedKeys(nodes) {
+				key := key
+				if err = n.appendNode(&key, nodes[key]); err != nil {
 					return err
 				}
 			}
@@ -228,9 +244,10 @@ func (n *Node) remove(value *Node) error {
 		delete(n.children, strconv.Itoa(*value.index))
 		n.dropindex(*value.index)
 	} else {
-		delete(n.children, *value.key)
+		n.dropChild(*value.key)
 	}
 	value.parent = nil
+	value.trivia = nil
 	return nil
 }
 
@@ -259,14 +276,11 @@ func (n *Node) appendNode(key *string, value *Node) error {
 	value.parent = n
 	value.key = key
 	if key != nil {
-		if old, ok := n.children[*key]; ok {
-			if old != value {
-				if err := n.remove(old); err != nil {
-					return err
-				}
-			}
+		if old, ok := n.children[*key]; ok && old != value {
+			old.parent = nil // replaced value keeps the position of the key
+			value.trivia, old.trivia = old.trivia, nil
 		}
-		n.children[*key] = value
+		n.setChild(*key, value)
 	} else {
 		index := len(n.children)
 		value.index = &index
@@ -275,6 +289,102 @@ func (n *Node) appendNode(key *string, value *Node) error {
 	return nil
 }
 
+// replace the child node with the new value, at the same key or index
+func (n *Node) replace(old *Node, value *Node) error {
+	if old.parent != n {
+		return errorRequest("wrong parent")
+	}
+	if old == value {
+		return nil
+	}
+	if n.isParentNode(value) {
+		return errorRequest("try to create infinite loop")
+	}
+	if value.parent != nil {
+		if err := value.parent.remove(value); err != nil {
+			return err
+		}
+	}
+	n.mark()
+	value.parent = n
+	value.key = old.key
+	value.index = old.index
+	if old.key != nil {
+		n.children[*old.key] = value
+	} else {
+		n.children[strconv.Itoa(*old.index)] = value
+	}
+	old.parent = nil
+	value.trivia, old.trivia = old.trivia, nil
+	return nil
+}
+
+// insert the value into the array at the index, shifting the next elements
+func (n *Node) insert(index int, value *Node) error {
+	if !n.IsArray() {
+		return errorType()
+	}
+	if n.isParentNode(value) {
+		return errorRequest("try to create infinite loop")
+	}
+	if value.parent != nil {
+		if err := value.parent.remove(value); err != nil {
+			return err
+		}
+	}
+	if index < 0 || index > len(n.children) {
+		return errorRequest("out of index %d", index)
+	}
+	for i := len(n.children) - 1; i >= index; i-- {
+		next := i + 1
+		current := n.children[strconv.Itoa(i)]
+		current.index = &next
+		n.children[strconv.Itoa(next)] = current
+	}
+	value.parent = n
+	value.key = nil
+	value.index = &index
+	n.children[strconv.Itoa(index)] = value
+	n.mark()
+	return nil
+}
+
+// assign replaces the value of the current node with the value of the given one, keeping the place in the tree.
+// Given node is consumed: its children are moved to the current node.
+func (n *Node) assign(value *Node) error {
+	if n == value {
+		return nil
+	}
+	if n.isParentNode(value) {
+		return errorRequest("try to create infinite loop")
+	}
+	if value.parent != nil {
+		if err := value.parent.remove(value); err != nil {
+			return err
+		}
+	}
+	if n.parent != nil {
+		n.parent.mark()
+	}
+	n.clear()
+	atomic.StoreInt32((*int32)(&n._type), int32(value._type))
+	n.data = value.data
+	n.borders = value.borders
+	n.dirty = value.dirty
+	n.value = atomic.Value{}
+	if current := value.value.Load(); current != nil {
+		n.value.Store(current)
+	}
+	n.children = value.children
+	n.keys = value.keys
+	for _, child := range n.children {
+		child.parent = n
+	}
+	value.children = nil
+	value.keys = nil
+	return nil
+}
+
 // mark node as dirty, with all parents (up the tree)
 func (n *Node) mark() {
 	node := n
@@ -292,6 +402,7 @@ func (n *Node) clear() {
 		n.children[key].parent = nil
 	}
 	n.children = nil
+	n.keys = nil
 }
 
 // isParentNode check if current node is one of the parents
@@ -1431,6 +1431,28 @@ func TestNode_Clone(t *testing.T) {
 	}
 }
 
+func TestNode_Clone_independent(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a":[1,2],"b":{"c":3}}`)))
+	clone := root.Clone()
+	if clone.MustKey("a").Parent() != clone {
+		t.Fatal("Clone() child has wrong parent")
+	}
+	if err := clone.MustKey("a").MustIndex(0).Delete(); err != nil {
+		t.Fatalf("Delete() error: %s", err)
+	}
+	if err := clone.MustKey("b").SetNull(); err != nil {
+		t.Fatalf("SetNull() error: %s", err)
+	}
+	if len(clone.MustKey("a").MustArray()) != 1 {
+		t.Errorf("Clone() array was not changed")
+	}
+	if result, err := Marshal(root); err != nil {
+		t.Errorf("Marshal() error: %s", err)
+	} else if string(result) != `{"a":[1,2],"b":{"c":3}}` {
+		t.Errorf("Clone() changed the base node: %s", result)
+	}
+}
+
 func ExampleNode_Clone() {
 	root := Must(Unmarshal(jsonPathTestData))
 	nodes, _ := root.JSONPath("$..price")
@@ -621,10 +621,10 @@ func TestNode_Keys(t *testing.T) {
 	if len(value) != 2 {
 		t.Errorf("Wrong root.Keys()")
 	}
-	if value[0] != "foo" && value[0] != "bar" {
+	if value[0] != "foo" {
 		t.Errorf("Wrong value in 0")
 	}
-	if value[1] != "foo" && value[1] != "bar" {
+	if value[1] != "bar" {
 		t.Errorf("Wrong value in 1")
 	}
 }
@@ -792,6 +792,24 @@ func TestNode_Eq(t *testing.T) {
 			right:    valueNode(nil, "123.5", Numeric, float64(123.5)),
 			expected: true,
 		},
+		{
+			name:     "big integers",
+			left:     Must(Unmarshal([]byte(`1234567890123456789`))),
+			right:    Must(Unmarshal([]byte(`1234567890123456788`))),
+			expected: false,
+		},
+		{
+			name:     "big integers equal",
+			left:     Must(Unmarshal([]byte(`1234567890123456789`))),
+			right:    Must(Unmarshal([]byte(`12345678901234567890e-1`))),
+			expected: true,
+		},
+		{
+			name:     "big integer and float",
+			left:     Must(Unmarshal([]byte(`1234567890123456768`))),
+			right:    valueNode(nil, "", Numeric, float64(1234567890123456768)),
+			expected: true,
+		},
 		{
 			name:     "blank array",
 			left:     valueNode(nil, "[]", Array, []*Node{}),
@@ -1473,6 +1491,19 @@ func TestArrayNode(t *testing.T) {
 	}
 }
 
+func TestObjectNode_keys(t *testing.T) {
+	node := ObjectNode("", map[string]*Node{
+		"a": NumericNode("", 1),
+		"b": NumericNode("", 2),
+		"c": NumericNode("", 3),
+	})
+	for _, key := range node.Keys() {
+		if child := node.MustKey(key); child.Key() != key {
+			t.Errorf("Key() = %s, expected %s", child.Key(), key)
+		}
+	}
+}
+
 func TestObjectNode(t *testing.T) {
 	objects := map[string]*Node{
 		"zero": NullNode("0"),
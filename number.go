@@ -0,0 +1,209 @@
+package ajson
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExponent limits the exponent of numbers, which are converted into the big.Int
+const maxExponent = 10000
+
+// NumberNode is constructor for Node with a Numeric value, given as the raw JSON number, e.g.: `1234567890123456789`.
+// The number is kept as is: Marshal emits it verbatim and all accessors, like GetInt64 or GetBigFloat, work from it.
+func NumberNode(key string, raw string) (current *Node, err error) {
+	current, err = Unmarshal([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	if !current.IsNumeric() {
+		return nil, errorRequest("wrong number '%s'", raw)
+	}
+	current.key = &key
+	return current, nil
+}
+
+// Int64Node is constructor for Node with a Numeric value, which keeps all digits of the int64 value.
+func Int64Node(key string, value int64) (current *Node) {
+	current, _ = NumberNode(key, strconv.FormatInt(value, 10))
+	return
+}
+
+// GetNumberString returns the JSON number as it is, if current type is Numeric, else: WrongType error.
+// Changed values are formatted the same way as Marshal does.
+func (n *Node) GetNumberString() (string, error) {
+	if n._type != Numeric {
+		return "", errorType()
+	}
+	if !n.dirty {
+		if !n.ready() {
+			return "", errorUnparsed()
+		}
+		return string(n.Source()), nil
+	}
+	value, err := n.GetNumeric()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
+
+// GetInt64 returns int64 value, if current type is Numeric and its value is the integer in the range of int64,
+// else: error. Value is taken from the source, so all digits are saved, e.g. for `9007199254740993`.
+func (n *Node) GetInt64() (int64, error) {
+	number, err := n.GetNumberString()
+	if err != nil {
+		return 0, err
+	}
+	if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return value, nil
+	}
+	value, err := n.GetBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsInt64() {
+		return 0, errorRequest("number %s is out of int64 range", number)
+	}
+	return value.Int64(), nil
+}
+
+// GetUint64 returns uint64 value, if current type is Numeric and its value is the integer in the range of uint64,
+// else: error. Value is taken from the source, so all digits are saved.
+func (n *Node) GetUint64() (uint64, error) {
+	number, err := n.GetNumberString()
+	if err != nil {
+		return 0, err
+	}
+	if value, err := strconv.ParseUint(number, 10, 64); err == nil {
+		return value, nil
+	}
+	value, err := n.GetBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsUint64() {
+		return 0, errorRequest("number %s is out of uint64 range", number)
+	}
+	return value.Uint64(), nil
+}
+
+// GetBigInt returns *big.Int value, if current type is Numeric and its value is the integer, e.g.: `1e3` or `12.0`,
+// else: error.
+func (n *Node) GetBigInt() (*big.Int, error) {
+	number, err := n.GetNumberString()
+	if err != nil {
+		return nil, err
+	}
+	if index := strings.IndexAny(number, "eE"); index != -1 {
+		if exponent, err := strconv.Atoi(number[index+1:]); err != nil || exponent > maxExponent || exponent < -maxExponent {
+			return nil, errorRequest("exponent of the number %s is too large", number)
+		}
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil, errorRequest("wrong number '%s'", number)
+	}
+	if !value.IsInt() {
+		return nil, errorRequest("number %s is not an integer", number)
+	}
+	return new(big.Int).Set(value.Num()), nil
+}
+
+// GetBigFloat returns *big.Float value, if current type is Numeric, else: WrongType error.
+// The precision of the value is enough to keep all digits of the source number.
+func (n *Node) GetBigFloat() (*big.Float, error) {
+	number, err := n.GetNumberString()
+	if err != nil {
+		return nil, err
+	}
+	// 4 bits for each decimal digit is more than log2(10)
+	prec := uint(len(number)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	value, _, err := big.ParseFloat(number, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, errorRequest("wrong number '%s'", number)
+	}
+	return value, nil
+}
+
+// MustNumberString returns the JSON number as it is, if current type is Numeric, else: panic if error happened
+func (n *Node) MustNumberString() (value string) {
+	value, err := n.GetNumberString()
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// MustInt64 returns int64 value, if current type is Numeric and its value is the integer, else: panic if error happened
+func (n *Node) MustInt64() (value int64) {
+	value, err := n.GetInt64()
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// MustUint64 returns uint64 value, if current type is Numeric and its value is the integer, else: panic if error happened
+func (n *Node) MustUint64() (value uint64) {
+	value, err := n.GetUint64()
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// MustBigInt returns *big.Int value, if current type is Numeric and its value is the integer, else: panic if error happened
+func (n *Node) MustBigInt() (value *big.Int) {
+	value, err := n.GetBigInt()
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// MustBigFloat returns *big.Float value, if current type is Numeric, else: panic if error happened
+func (n *Node) MustBigFloat() (value *big.Float) {
+	value, err := n.GetBigFloat()
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// equalNumbers compares numbers exactly, if both of them are integers, e.g.: `1234567890123456789`,
+// else as float64 values.
+func equalNumbers(left, right *Node) (bool, error) {
+	if lint := left.exactInteger(); lint != nil {
+		if rint := right.exactInteger(); rint != nil {
+			return lint.Cmp(rint) == 0, nil
+		}
+	}
+	lnum, rnum, err := _floats(left, right)
+	if err != nil {
+		return false, err
+	}
+	return lnum == rnum, nil
+}
+
+// exactInteger returns the exact value of the integer number, or nil if the number is not an integer.
+// Changed values are taken as they are stored, not as they are formatted.
+func (n *Node) exactInteger() *big.Int {
+	if !n.dirty {
+		value, err := n.GetBigInt()
+		if err != nil {
+			return nil
+		}
+		return value
+	}
+	value, err := n.GetNumeric()
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) || value != math.Trunc(value) {
+		return nil
+	}
+	result, _ := big.NewFloat(value).Int(nil)
+	return result
+}
@@ -0,0 +1,208 @@
+package ajson
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNumberNode(t *testing.T) {
+	tests := []string{`0`, `-1`, `12345678901234567890123`, `9007199254740993`, `1.50`, `2E-3`, `-0.000000000000000000000000001`}
+	for _, raw := range tests {
+		t.Run(raw, func(t *testing.T) {
+			node, err := NumberNode("key", raw)
+			if err != nil {
+				t.Fatalf("NumberNode() error = %v", err)
+			}
+			if node.Key() != "key" || !node.IsNumeric() {
+				t.Errorf("NumberNode() wrong node")
+			}
+			root := ArrayNode("", []*Node{node})
+			result, err := Marshal(root)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != "["+raw+"]" {
+				t.Errorf("Marshal() = %s, expected [%s]", result, raw)
+			}
+		})
+	}
+	for _, raw := range []string{``, `01`, `1.`, `"1"`, `null`, `1 2`, `0x10`} {
+		if _, err := NumberNode("", raw); err == nil {
+			t.Errorf("NumberNode(%q) expected error", raw)
+		}
+	}
+}
+
+func TestInt64Node(t *testing.T) {
+	for _, value := range []int64{0, -1, 1<<53 + 1, math.MaxInt64, math.MinInt64} {
+		node := Int64Node("", value)
+		if result := node.MustInt64(); result != value {
+			t.Errorf("Int64Node() = %d, expected %d", result, value)
+		}
+		if result, err := Marshal(node); err != nil || string(result) != fmt.Sprint(value) {
+			t.Errorf("Marshal() = %s, expected %d", result, value)
+		}
+	}
+}
+
+func TestNode_GetInt64(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		err      bool
+	}{
+		{input: `9007199254740993`, expected: 9007199254740993},
+		{input: `-9223372036854775808`, expected: math.MinInt64},
+		{input: `9223372036854775807`, expected: math.MaxInt64},
+		{input: `1e3`, expected: 1000},
+		{input: `12.000`, expected: 12},
+		{input: `-0`, expected: 0},
+		{input: `9223372036854775808`, err: true},
+		{input: `1.5`, err: true},
+		{input: `1e-3`, err: true},
+		{input: `1e100000`, err: true},
+		{input: `"1"`, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			value, err := Must(Unmarshal([]byte(test.input))).GetInt64()
+			if test.err {
+				if err == nil {
+					t.Errorf("GetInt64() expected error, got %d", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetInt64() error = %v", err)
+			}
+			if value != test.expected {
+				t.Errorf("GetInt64() = %d, expected %d", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_GetUint64(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+		err      bool
+	}{
+		{input: `18446744073709551615`, expected: math.MaxUint64},
+		{input: `1234567890123456789`, expected: 1234567890123456789},
+		{input: `1.8e1`, expected: 18},
+		{input: `18446744073709551616`, err: true},
+		{input: `-1`, err: true},
+		{input: `0.5`, err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			value, err := Must(Unmarshal([]byte(test.input))).GetUint64()
+			if test.err {
+				if err == nil {
+					t.Errorf("GetUint64() expected error, got %d", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetUint64() error = %v", err)
+			}
+			if value != test.expected {
+				t.Errorf("GetUint64() = %d, expected %d", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_GetBigInt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `123456789012345678901234567890`, expected: "123456789012345678901234567890"},
+		{input: `-1.5e30`, expected: "-1500000000000000000000000000000"},
+		{input: `100e-2`, expected: "1"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			value, err := Must(Unmarshal([]byte(test.input))).GetBigInt()
+			if err != nil {
+				t.Fatalf("GetBigInt() error = %v", err)
+			}
+			if value.String() != test.expected {
+				t.Errorf("GetBigInt() = %s, expected %s", value, test.expected)
+			}
+		})
+	}
+	for _, input := range []string{`1.1`, `1e-1`, `1e10001`, `true`} {
+		if _, err := Must(Unmarshal([]byte(input))).GetBigInt(); err == nil {
+			t.Errorf("GetBigInt(%s) expected error", input)
+		}
+	}
+}
+
+func TestNode_GetBigFloat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `0.1`, expected: "0.1"},
+		{input: `3.14159265358979323846264338327950288`, expected: "3.14159265358979323846264338327950288"},
+		{input: `-12345678901234567890.125`, expected: "-12345678901234567890.125"},
+		{input: `1e-30`, expected: "1e-30"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			value, err := Must(Unmarshal([]byte(test.input))).GetBigFloat()
+			if err != nil {
+				t.Fatalf("GetBigFloat() error = %v", err)
+			}
+			if result := value.Text('g', len(test.expected)); result != test.expected && value.Text('f', -1) != test.expected {
+				t.Errorf("GetBigFloat() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+	if _, err := StringNode("", "1").GetBigFloat(); err == nil {
+		t.Errorf("GetBigFloat() expected error")
+	}
+}
+
+func TestNode_GetNumberString(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"id": 1234567890123456789012, "price": 1.50}`)))
+	if value := root.MustKey("id").MustNumberString(); value != "1234567890123456789012" {
+		t.Errorf("GetNumberString() = %s", value)
+	}
+	if value := root.MustKey("price").MustNumberString(); value != "1.50" {
+		t.Errorf("GetNumberString() = %s", value)
+	}
+	if err := root.AppendObject("count", NumericNode("", 3)); err != nil {
+		t.Fatalf("AppendObject() error = %v", err)
+	}
+	if value := root.MustKey("count").MustNumberString(); value != "3" {
+		t.Errorf("GetNumberString() = %s", value)
+	}
+	if result := marshalString(root); result != `{"id":1234567890123456789012,"price":1.50,"count":3}` {
+		t.Errorf("Marshal() = %s", result)
+	}
+	if _, err := NullNode("").GetNumberString(); err == nil {
+		t.Errorf("GetNumberString() expected error")
+	}
+}
+
+func ExampleNode_GetInt64() {
+	root := Must(Unmarshal([]byte(`{"id": 1433720429371047937, "text": "snowflake"}`)))
+	id, err := root.MustKey("id").GetInt64()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(id)
+	fmt.Println(int64(root.MustKey("id").MustNumeric()))
+	_ = root.AppendObject("reply_to", Int64Node("", id+1))
+	result, _ := Marshal(root)
+	fmt.Printf("%s\n", result)
+	// Output:
+	// 1433720429371047937
+	// 1433720429371047936
+	// {"id":1433720429371047937,"text":"snowflake","reply_to":1433720429371047938}
+}
@@ -0,0 +1,275 @@
+package ajson
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ApplyPatch applies JSON Patch (RFC 6902) to the root node.
+//
+// Patch should be an Array of operations: `add`, `remove`, `replace`, `move`, `copy` and `test`, e.g.:
+//
+//	[
+//		{"op": "test", "path": "/a/b/c", "value": "foo"},
+//		{"op": "remove", "path": "/a/b/c"},
+//		{"op": "add", "path": "/a/b/c", "value": ["foo", "bar"]},
+//		{"op": "replace", "path": "/a/b/c", "value": 42},
+//		{"op": "move", "from": "/a/b/c", "path": "/a/b/d"},
+//		{"op": "copy", "from": "/a/b/d", "path": "/a/b/e"}
+//	]
+//
+// Patch is applied atomically: if any operation fails, the root node stays unchanged.
+// Values of the patch are copied, so the patch node can be reused.
+func ApplyPatch(root *Node, patch *Node) error {
+	if root == nil {
+		return errorRequest("root should not be nil")
+	}
+	operations, err := parsePatch(patch)
+	if err != nil {
+		return err
+	}
+	if err = applyPatch(root.Clone(), operations); err != nil {
+		return err
+	}
+	return applyPatch(root, operations)
+}
+
+// CreatePatch returns JSON Patch (RFC 6902), which transforms the node a into the node b.
+func CreatePatch(a, b *Node) (*Node, error) {
+	if a == nil || b == nil {
+		return nil, errorRequest("nodes should not be nil")
+	}
+	result := make([]*Node, 0)
+	err := createPatch(a, b, "", &result)
+	if err != nil {
+		return nil, err
+	}
+	return ArrayNode("", result), nil
+}
+
+// patchOperation is the parsed operation of JSON Patch
+type patchOperation struct {
+	op    string
+	path  []string
+	from  []string
+	value *Node
+}
+
+func parsePatch(patch *Node) (result []*patchOperation, err error) {
+	if patch == nil || !patch.IsArray() {
+		return nil, errorRequest("patch should be an array")
+	}
+	elements, err := patch.GetArray()
+	if err != nil {
+		return nil, err
+	}
+	result = make([]*patchOperation, len(elements))
+	for i, element := range elements {
+		if !element.IsObject() {
+			return nil, errorRequest("patch operation %d should be an object", i)
+		}
+		operation := new(patchOperation)
+		if operation.op, err = patchString(element, "op", i); err != nil {
+			return nil, err
+		}
+		if operation.path, err = patchPointer(element, "path", i); err != nil {
+			return nil, err
+		}
+		switch operation.op {
+		case "add", "replace", "test":
+			if operation.value, err = element.GetKey("value"); err != nil {
+				return nil, errorRequest("patch operation %d should have 'value'", i)
+			}
+		case "move", "copy":
+			if operation.from, err = patchPointer(element, "from", i); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, errorRequest("unknown patch operation '%s'", operation.op)
+		}
+		result[i] = operation
+	}
+	return result, nil
+}
+
+func patchString(element *Node, key string, i int) (string, error) {
+	node, err := element.GetKey(key)
+	if err != nil || !node.IsString() {
+		return "", errorRequest("patch operation %d should have '%s' string", i, key)
+	}
+	return node.GetString()
+}
+
+func patchPointer(element *Node, key string, i int) ([]string, error) {
+	pointer, err := patchString(element, key, i)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONPointer(pointer)
+}
+
+func applyPatch(root *Node, operations []*patchOperation) (err error) {
+	var node *Node
+	for _, operation := range operations {
+		switch operation.op {
+		case "add":
+			err = patchAdd(root, operation.path, operation.value.Clone())
+		case "remove":
+			if len(operation.path) == 0 {
+				return errorRequest("can't remove the root node")
+			}
+			if node, err = root.getPointer(operation.path); err == nil {
+				err = node.Delete()
+			}
+		case "replace":
+			if node, err = root.getPointer(operation.path); err == nil {
+				err = node.assign(operation.value.Clone())
+			}
+		case "move":
+			if isPointerPrefix(operation.from, operation.path) {
+				if len(operation.from) == len(operation.path) {
+					continue
+				}
+				return errorRequest("can't move the node into its own child")
+			}
+			if node, err = root.getPointer(operation.from); err == nil {
+				if err = node.Delete(); err != nil {
+					return err
+				}
+				err = patchAdd(root, operation.path, node)
+			}
+		case "copy":
+			if node, err = root.getPointer(operation.from); err == nil {
+				err = patchAdd(root, operation.path, node.Clone())
+			}
+		case "test":
+			if node, err = root.getPointer(operation.path); err == nil {
+				var ok bool
+				if ok, err = node.Eq(operation.value); err == nil && !ok {
+					err = errorRequest("test operation failed for '%s'", pointerString(operation.path))
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchAdd adds the value by the path: replaces the root or a key of an Object, or inserts it into an Array
+func patchAdd(root *Node, path []string, value *Node) error {
+	if len(path) == 0 {
+		return root.assign(value)
+	}
+	parent, err := root.getPointer(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	token := path[len(path)-1]
+	switch parent.Type() {
+	case Object:
+		return parent.AppendObject(token, value)
+	case Array:
+		index, err := pointerIndex(parent, token)
+		if err != nil {
+			return err
+		}
+		return parent.insert(index, value)
+	}
+	return errorRequest("wrong pointer token '%s' for the scalar node", token)
+}
+
+// isPointerPrefix checks if the prefix is the same or the parent pointer of the path
+func isPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func pointerString(tokens []string) string {
+	result := ""
+	for _, token := range tokens {
+		result += "/" + pointerEscape.Replace(token)
+	}
+	return result
+}
+
+func createPatch(a, b *Node, pointer string, result *[]*Node) error {
+	if a.Type() != b.Type() {
+		*result = append(*result, patchNode("replace", pointer, b))
+		return nil
+	}
+	switch a.Type() {
+	case Object:
+		keys := make([]string, 0, len(a.children)+len(b.children))
+		for key := range a.children {
+			keys = append(keys, key)
+		}
+		for key := range b.children {
+			if _, ok := a.children[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := pointer + "/" + pointerEscape.Replace(key)
+			left, lok := a.children[key]
+			right, rok := b.children[key]
+			switch {
+			case !rok:
+				*result = append(*result, patchNode("remove", path, nil))
+			case !lok:
+				*result = append(*result, patchNode("add", path, right))
+			default:
+				if err := createPatch(left, right, path, result); err != nil {
+					return err
+				}
+			}
+		}
+	case Array:
+		size := a.Size()
+		if b.Size() < size {
+			size = b.Size()
+		}
+		for i := 0; i < size; i++ {
+			path := pointer + "/" + strconv.Itoa(i)
+			if err := createPatch(a.children[strconv.Itoa(i)], b.children[strconv.Itoa(i)], path, result); err != nil {
+				return err
+			}
+		}
+		for i := size; i < b.Size(); i++ {
+			*result = append(*result, patchNode("add", pointer+"/"+strconv.Itoa(i), b.children[strconv.Itoa(i)]))
+		}
+		for i := a.Size() - 1; i >= size; i-- {
+			*result = append(*result, patchNode("remove", pointer+"/"+strconv.Itoa(i), nil))
+		}
+	default:
+		ok, err := a.Eq(b)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			*result = append(*result, patchNode("replace", pointer, b))
+		}
+	}
+	return nil
+}
+
+func patchNode(op, path string, value *Node) *Node {
+	operation := map[string]*Node{
+		"op":   StringNode("", op),
+		"path": StringNode("", path),
+	}
+	if value != nil {
+		operation["value"] = value.Clone()
+	}
+	return ObjectNode("", operation)
+}
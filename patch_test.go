@@ -0,0 +1,213 @@
+package ajson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func marshalString(node *Node) string {
+	result, err := Marshal(node)
+	if err != nil {
+		return err.Error()
+	}
+	return string(result)
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		patch    string
+		expected string
+	}{
+		// RFC 6902 appendix A
+		{name: "A.1", input: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, expected: `{"baz":"qux","foo":"bar"}`},
+		{name: "A.2", input: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, expected: `{"foo":["bar","qux","baz"]}`},
+		{name: "A.3", input: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expected: `{"foo":"bar"}`},
+		{name: "A.4", input: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, expected: `{"foo":["bar","baz"]}`},
+		{name: "A.5", input: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, expected: `{"baz":"boo","foo":"bar"}`},
+		{name: "A.6", input: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "A.7", input: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, expected: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "A.8", input: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, expected: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "A.10", input: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, expected: `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{name: "A.11", input: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, expected: `{"baz":"qux","foo":"bar"}`},
+		{name: "A.14", input: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10}]`, expected: `{"/":9,"~1":10}`},
+		{name: "A.16", input: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, expected: `{"foo":["bar",["abc","def"]]}`},
+		// other cases
+		{name: "add null", input: `{}`, patch: `[{"op":"add","path":"/a","value":null}]`, expected: `{"a":null}`},
+		{name: "add existing", input: `{"a":[1]}`, patch: `[{"op":"add","path":"/a","value":2}]`, expected: `{"a":2}`},
+		{name: "add root", input: `{"a":1}`, patch: `[{"op":"add","path":"","value":[1,2]}]`, expected: `[1,2]`},
+		{name: "replace root", input: `[1]`, patch: `[{"op":"replace","path":"","value":{"b":2}}]`, expected: `{"b":2}`},
+		{name: "replace container", input: `{"a":{"b":1}}`, patch: `[{"op":"replace","path":"/a","value":[true]}]`, expected: `{"a":[true]}`},
+		{name: "copy", input: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/d","value":2}]`, expected: `{"a":{"b":1},"c":{"b":1,"d":2}}`},
+		{name: "move itself", input: `{"a":1}`, patch: `[{"op":"move","from":"/a","path":"/a"}]`, expected: `{"a":1}`},
+		{name: "move up", input: `{"a":{"b":{"c":1}}}`, patch: `[{"op":"move","from":"/a/b","path":"/a"}]`, expected: `{"a":{"c":1}}`},
+		{name: "test object", input: `{"a":{"b":[1,2]}}`, patch: `[{"op":"test","path":"/a","value":{"b":[1,2]}}]`, expected: `{"a":{"b":[1,2]}}`},
+		{name: "empty", input: `{"a":1}`, patch: `[]`, expected: `{"a":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := ApplyPatch(root, Must(Unmarshal([]byte(test.patch)))); err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			expected := Must(Unmarshal([]byte(test.expected)))
+			if ok, err := root.Eq(expected); err != nil || !ok {
+				t.Errorf("ApplyPatch() = %s, expected %s", marshalString(root), test.expected)
+			}
+		})
+	}
+}
+
+func TestApplyPatch_error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		patch string
+	}{
+		// RFC 6902 appendix A
+		{name: "A.9", input: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{name: "A.12", input: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{name: "A.15", input: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":"10"}]`},
+		// other cases
+		{name: "not array", input: `{}`, patch: `{"op":"remove","path":"/a"}`},
+		{name: "not object", input: `{}`, patch: `[1]`},
+		{name: "no op", input: `{}`, patch: `[{"path":"/a"}]`},
+		{name: "unknown op", input: `{}`, patch: `[{"op":"drop","path":"/a"}]`},
+		{name: "no path", input: `{}`, patch: `[{"op":"remove"}]`},
+		{name: "wrong path", input: `{}`, patch: `[{"op":"remove","path":"a"}]`},
+		{name: "no value", input: `{}`, patch: `[{"op":"add","path":"/a"}]`},
+		{name: "no from", input: `{"a":1}`, patch: `[{"op":"copy","path":"/b"}]`},
+		{name: "remove missing", input: `{}`, patch: `[{"op":"remove","path":"/a"}]`},
+		{name: "remove root", input: `{}`, patch: `[{"op":"remove","path":""}]`},
+		{name: "replace missing", input: `{}`, patch: `[{"op":"replace","path":"/a","value":1}]`},
+		{name: "add out of index", input: `[1]`, patch: `[{"op":"add","path":"/2","value":1}]`},
+		{name: "add to scalar", input: `{"a":1}`, patch: `[{"op":"add","path":"/a/b","value":1}]`},
+		{name: "move into child", input: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`},
+		{name: "move missing", input: `{}`, patch: `[{"op":"move","from":"/a","path":"/b"}]`},
+		{name: "test big integer", input: `{"id":1234567890123456789}`, patch: `[{"op":"test","path":"/id","value":1234567890123456788}]`},
+		{name: "copy missing", input: `{}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := ApplyPatch(root, Must(Unmarshal([]byte(test.patch)))); err == nil {
+				t.Errorf("ApplyPatch() expected error")
+			}
+		})
+	}
+}
+
+func TestApplyPatch_atomic(t *testing.T) {
+	input := `{"a":[1,2,3],"b":{"c":"d"}}`
+	root := Must(Unmarshal([]byte(input)))
+	patch := Must(Unmarshal([]byte(`[
+		{"op":"remove","path":"/a/0"},
+		{"op":"replace","path":"/b/c","value":"e"},
+		{"op":"test","path":"/b/c","value":"d"}
+	]`)))
+	if err := ApplyPatch(root, patch); err == nil {
+		t.Fatalf("ApplyPatch() expected error")
+	}
+	if string(root.Source()) != input {
+		t.Errorf("ApplyPatch() changed the node: %s", marshalString(root))
+	}
+}
+
+func TestApplyPatch_reuse(t *testing.T) {
+	patch := Must(Unmarshal([]byte(`[{"op":"add","path":"/a/-","value":{"b":1}}]`)))
+	first := Must(Unmarshal([]byte(`{"a":[]}`)))
+	second := Must(Unmarshal([]byte(`{"a":[]}`)))
+	if err := ApplyPatch(first, patch); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if err := ApplyPatch(second, patch); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if err := first.MustKey("a").MustIndex(0).SetPointer("/b", NumericNode("", 2)); err != nil {
+		t.Fatalf("SetPointer() error = %v", err)
+	}
+	if result := marshalString(second); result != `{"a":[{"b":1}]}` {
+		t.Errorf("ApplyPatch() = %s, expected {\"a\":[{\"b\":1}]}", result)
+	}
+	if value := patch.MustIndex(0).MustKey("value"); value.MustKey("b").MustNumeric() != 1 {
+		t.Errorf("ApplyPatch() changed the patch: %s", marshalString(patch))
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{name: "equal", a: `{"a":[1,{"b":null}]}`, b: `{"a":[1,{"b":null}]}`, expected: `[]`},
+		{name: "scalar", a: `1`, b: `2`, expected: `[{"op":"replace","path":"","value":2}]`},
+		{name: "type", a: `{"a":1}`, b: `{"a":"1"}`, expected: `[{"op":"replace","path":"/a","value":"1"}]`},
+		{name: "add key", a: `{}`, b: `{"a~b":{"c":1}}`, expected: `[{"op":"add","path":"/a~0b","value":{"c":1}}]`},
+		{name: "remove key", a: `{"a/b":1,"c":2}`, b: `{"c":2}`, expected: `[{"op":"remove","path":"/a~1b"}]`},
+		{name: "append", a: `[1]`, b: `[1,2,3]`, expected: `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`},
+		{name: "truncate", a: `[1,2,3]`, b: `[1]`, expected: `[{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+		{name: "big integer", a: `{"id":1234567890123456789}`, b: `{"id":1234567890123456788}`, expected: `[{"op":"replace","path":"/id","value":1234567890123456788}]`},
+		{name: "nested", a: `{"a":{"b":[1,2]},"c":1}`, b: `{"a":{"b":[1,3]},"d":1}`, expected: `[{"op":"replace","path":"/a/b/1","value":3},{"op":"remove","path":"/c"},{"op":"add","path":"/d","value":1}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := Must(Unmarshal([]byte(test.a)))
+			b := Must(Unmarshal([]byte(test.b)))
+			patch, err := CreatePatch(a, b)
+			if err != nil {
+				t.Fatalf("CreatePatch() error = %v", err)
+			}
+			expected := Must(Unmarshal([]byte(test.expected)))
+			if ok, err := patch.Eq(expected); err != nil || !ok {
+				t.Errorf("CreatePatch() = %s, expected %s", marshalString(patch), test.expected)
+			}
+			if err = ApplyPatch(a, patch); err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			if ok, err := a.Eq(b); err != nil || !ok {
+				t.Errorf("ApplyPatch(CreatePatch()) = %s, expected %s", marshalString(a), test.b)
+			}
+		})
+	}
+}
+
+func TestPatch_nil(t *testing.T) {
+	node := Must(Unmarshal([]byte(`{"a":1}`)))
+	patch := Must(Unmarshal([]byte(`[{"op":"remove","path":"/a"}]`)))
+	if err := ApplyPatch(nil, patch); err == nil {
+		t.Errorf("ApplyPatch() expected error for nil root")
+	}
+	if err := ApplyPatch(node, nil); err == nil {
+		t.Errorf("ApplyPatch() expected error for nil patch")
+	}
+	if _, err := CreatePatch(nil, node); err == nil {
+		t.Errorf("CreatePatch() expected error for nil a")
+	}
+	if _, err := CreatePatch(node, nil); err == nil {
+		t.Errorf("CreatePatch() expected error for nil b")
+	}
+	if _, err := CreatePatch(nil, nil); err == nil {
+		t.Errorf("CreatePatch() expected error for nil nodes")
+	}
+}
+
+func ExampleApplyPatch() {
+	root := Must(Unmarshal([]byte(`{"name":"Alice","tags":["a","c"]}`)))
+	patch := Must(Unmarshal([]byte(`[
+		{"op": "test", "path": "/name", "value": "Alice"},
+		{"op": "add", "path": "/tags/1", "value": "b"},
+		{"op": "copy", "from": "/name", "path": "/tags/-"}
+	]`)))
+	if err := ApplyPatch(root, patch); err != nil {
+		panic(err)
+	}
+	result, err := Marshal(root.MustKey("tags"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", result)
+	// Output:
+	// ["a","b","c","Alice"]
+}
@@ -0,0 +1,438 @@
+package ajson
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Path is the compiled JSONPath.
+//
+// Path contains already tokenized commands with compiled filters and scripts, so it can be compiled once and
+// evaluated many times. Path is immutable and safe for concurrent use by multiple goroutines.
+type Path struct {
+	path     string
+	segments []*segment
+}
+
+// kinds of the compiled JSONPath commands
+const (
+	segmentRoot     = iota // `$`
+	segmentCurrent         // `@`
+	segmentDescent         // `..`
+	segmentWildcard        // `*`
+	segmentSlice           // `[start:end:step]`
+	segmentFilter          // `?(...)`
+	segmentScript          // `(...)`
+	segmentKeys            // keys, indexes and unions of them
+)
+
+// segment is the compiled JSONPath command
+type segment struct {
+	kind    int
+	path    string
+	index   int
+	offset  int
+	command string
+	keys    []*pathKey
+	expr    *Expression
+}
+
+// pathKey is the part of the slice or union, with the prepared script if the key is an expression `(...)`
+type pathKey struct {
+	value string
+	expr  *Expression
+	err   error
+}
+
+// CompileJSONPath parses the JSONPath and prepares it to be evaluated.
+//
+// Example:
+//
+//	path, err := CompileJSONPath("$.store.book[?(@.price < 10)].title")
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, data := range documents {
+//		root, _ := Unmarshal(data)
+//		titles, _ := path.Evaluate(root)
+//	}
+func CompileJSONPath(path string) (*Path, error) {
+	commands, offsets, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := compileSegments(path, commands, offsets)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{path: path, segments: segments}, nil
+}
+
+// MustCompileJSONPath is like CompileJSONPath but panics if the path can't be parsed.
+func MustCompileJSONPath(path string) *Path {
+	result, err := CompileJSONPath(path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Evaluate returns slice of found elements in the node, by the compiled JSONPath.
+func (p *Path) Evaluate(node *Node) (result []*Node, err error) {
+	return evaluateSegments(node, p.segments)
+}
+
+// String returns the source of the JSONPath
+func (p *Path) String() string {
+	return p.path
+}
+
+// compileSegments compiles commands of the path. Offsets are positions of the commands in the path, if they are known.
+func compileSegments(path string, commands []string, offsets []int) (result []*segment, err error) {
+	result = make([]*segment, len(commands))
+	for i, cmd := range commands {
+		result[i] = &segment{path: path, index: i, offset: -1, command: cmd}
+		if i < len(offsets) {
+			result[i].offset = offsets[i]
+		}
+		if err = result[i].compile(); err != nil {
+			return nil, result[i].error(nil, err)
+		}
+	}
+	return result, nil
+}
+
+// compile prepares the segment by its command
+func (s *segment) compile() (err error) {
+	cmd := s.command
+	tokens, err := tokenize(cmd)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cmd == "$":
+		s.kind = segmentRoot
+	case cmd == "@":
+		s.kind = segmentCurrent
+	case cmd == "..":
+		s.kind = segmentDescent
+	case cmd == "*":
+		s.kind = segmentWildcard
+	case tokens.exists(":"):
+		if tokens.count(":") > 3 {
+			return errorRequest("slice must contains no more than 2 colons, got '%s'", cmd)
+		}
+		s.kind = segmentSlice
+		s.keys = compileKeys(tokens.slice(":"))
+	case strings.HasPrefix(cmd, "?(") && strings.HasSuffix(cmd, ")"):
+		s.kind = segmentFilter
+		s.expr, err = CompileExpression(cmd[2 : len(cmd)-1])
+	case strings.HasPrefix(cmd, "(") && strings.HasSuffix(cmd, ")"):
+		s.kind = segmentScript
+		s.expr, err = CompileExpression(cmd[1 : len(cmd)-1])
+	default:
+		s.kind = segmentKeys
+		keys := []string{cmd}
+		if tokens.exists(",") {
+			keys = tokens.slice(",")
+			if len(keys) == 0 {
+				return errorRequest("union has no keys")
+			}
+		}
+		s.keys = compileKeys(keys)
+	}
+	return err
+}
+
+// error wraps the cause of the failed segment. Node is the node, on which the segment was evaluated, if any.
+func (s *segment) error(node *Node, cause error) error {
+	result := &PathError{
+		Path:    s.path,
+		Segment: s.index,
+		Command: s.command,
+		Offset:  s.offset,
+		Err:     cause,
+	}
+	if node != nil {
+		result.Node = node.Path()
+	}
+	return result
+}
+
+// compileKeys prepares scripts of the keys. Errors of the scripts are returned only on the evaluation,
+// when the key is used with an array.
+func compileKeys(keys []string) []*pathKey {
+	result := make([]*pathKey, len(keys))
+	for i, key := range keys {
+		result[i] = &pathKey{value: key}
+		if key != "(@.length)" && strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+			result[i].expr, result[i].err = CompileExpression(key[1 : len(key)-1])
+		}
+	}
+	return result
+}
+
+func evaluateSegments(node *Node, segments []*segment) (result []*Node, err error) {
+	result = make([]*Node, 0)
+	var (
+		temporary   []*Node
+		ikeys       [3]int
+		fkeys       [3]float64
+		num         int
+		key         string
+		ok          bool
+		value, temp *Node
+		float       float64
+	)
+	for i, segment := range segments {
+		switch segment.kind {
+		case segmentRoot: // root element
+			if i == 0 {
+				result = append(result, node.root())
+			}
+		case segmentCurrent: // current element
+			if i == 0 {
+				result = append(result, node)
+			}
+		case segmentDescent: // recursive descent
+			temporary = make([]*Node, 0)
+			for _, element := range result {
+				temporary = append(temporary, recursiveChildren(element)...)
+			}
+			result = append(result, temporary...)
+		case segmentWildcard: // wildcard
+			temporary = make([]*Node, 0)
+			for _, element := range result {
+				temporary = append(temporary, element.Inheritors()...)
+			}
+			result = temporary
+		case segmentSlice: // array slice operator
+			keys := segment.keys
+			temporary = make([]*Node, 0)
+			for _, element := range result {
+				if element.IsArray() && element.Size() > 0 {
+					if fkeys[0], err = keys[0].number(element, math.NaN()); err != nil {
+						return nil, segment.error(element, err)
+					}
+					if fkeys[1], err = keys[1].number(element, math.NaN()); err != nil {
+						return nil, segment.error(element, err)
+					}
+					if len(keys) < 3 {
+						fkeys[2] = 1
+					} else if fkeys[2], err = keys[2].number(element, 1); err != nil {
+						return nil, segment.error(element, err)
+					}
+
+					ikeys[2] = int(fkeys[2])
+					if ikeys[2] == 0 {
+						return nil, segment.error(element, errorRequest("slice step can't be zero"))
+					}
+
+					if math.IsNaN(fkeys[0]) {
+						if ikeys[2] > 0 {
+							ikeys[0] = 0
+						} else {
+							ikeys[0] = element.Size() - 1
+						}
+					} else {
+						ikeys[0] = getPositiveIndex(int(fkeys[0]), element.Size())
+					}
+					if math.IsNaN(fkeys[1]) {
+						if ikeys[2] > 0 {
+							ikeys[1] = element.Size()
+						} else {
+							ikeys[1] = -1
+						}
+					} else {
+						ikeys[1] = getPositiveIndex(int(fkeys[1]), element.Size())
+					}
+
+					if ikeys[2] > 0 {
+						if ikeys[0] < 0 {
+							ikeys[0] = 0
+						}
+						if ikeys[1] > element.Size() {
+							ikeys[1] = element.Size()
+						}
+
+						for i := ikeys[0]; i < ikeys[1]; i += ikeys[2] {
+							value, ok := element.children[strconv.Itoa(i)]
+							if ok {
+								temporary = append(temporary, value)
+							}
+						}
+					} else if ikeys[2] < 0 {
+						if ikeys[0] > element.Size() {
+							ikeys[0] = element.Size()
+						}
+						if ikeys[1] < -1 {
+							ikeys[1] = -1
+						}
+
+						for i := ikeys[0]; i > ikeys[1]; i += ikeys[2] {
+							value, ok := element.children[strconv.Itoa(i)]
+							if ok {
+								temporary = append(temporary, value)
+							}
+						}
+					}
+				}
+			}
+			result = temporary
+		case segmentFilter: // applies a filter (script) expression
+			temporary = make([]*Node, 0)
+			for _, element := range result {
+				if element.isContainer() {
+					for _, temp = range element.Inheritors() {
+						value, err = segment.expr.Evaluate(temp)
+						if err != nil {
+							return nil, segment.error(temp, err)
+						}
+						if value != nil {
+							ok, err = boolean(value)
+							if err != nil || !ok {
+								continue
+							}
+							temporary = append(temporary, temp)
+						}
+					}
+				}
+			}
+			result = temporary
+		case segmentScript: // script expression, using the underlying script engine
+			temporary = make([]*Node, 0)
+			for _, element := range result {
+				if !element.isContainer() {
+					continue
+				}
+				temp, err = segment.expr.Evaluate(element)
+				if err != nil {
+					return nil, segment.error(element, err)
+				}
+				if temp != nil {
+					value = nil
+					switch temp.Type() {
+					case String:
+						key, err = temp.GetString()
+						if err != nil {
+							return nil, segment.error(element, err)
+						}
+						value = element.children[key]
+					case Numeric:
+						num, err = temp.getInteger()
+						if err == nil { // INTEGER
+							if num < 0 {
+								key = strconv.Itoa(element.Size() - num)
+							} else {
+								key = strconv.Itoa(num)
+							}
+						} else {
+							float, err = temp.GetNumeric()
+							if err != nil {
+								return nil, segment.error(element, err)
+							}
+							key = strconv.FormatFloat(float, 'g', -1, 64)
+						}
+						value = element.children[key]
+					case Bool:
+						ok, err = temp.GetBool()
+						if err != nil {
+							return nil, segment.error(element, err)
+						}
+						if ok {
+							temporary = append(temporary, element.Inheritors()...)
+						}
+						continue
+						// case Array: // get all keys from element via array values
+					}
+					if value != nil {
+						temporary = append(temporary, value)
+					}
+				}
+			}
+			result = temporary
+		default: // try to get by key & Union
+			temporary = make([]*Node, 0)
+			for _, pkey := range segment.keys { // fixme
+				key = pkey.value
+				for _, element := range result {
+					if element.IsArray() {
+						if key == "length" || key == "'length'" || key == "\"length\"" {
+							value, err = functions["length"](element)
+							if err != nil {
+								return nil, segment.error(element, err)
+							}
+							ok = true
+						} else if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+							fkeys[0], err = pkey.number(element, math.NaN())
+							if err != nil {
+								return nil, segment.error(element, err)
+							}
+							if math.IsNaN(fkeys[0]) {
+								return nil, segment.error(element, errorRequest("wrong index '%s'", key))
+							}
+							if element.Size() == 0 {
+								ok = false
+							} else {
+								num = getPositiveIndex(int(fkeys[0]), element.Size())
+								value, ok = element.children[strconv.Itoa(num)]
+							}
+						} else {
+							key, _ = str(key)
+							num, err = strconv.Atoi(key)
+							if err != nil || element.Size() == 0 {
+								ok = false
+								err = nil
+							} else {
+								num = getPositiveIndex(num, element.Size())
+								value, ok = element.children[strconv.Itoa(num)]
+							}
+						}
+
+					} else if element.IsObject() {
+						key, _ = str(key)
+						value, ok = element.children[key]
+					}
+					if ok {
+						temporary = append(temporary, value)
+						ok = false
+					}
+				}
+			}
+			result = temporary
+		}
+	}
+	return
+}
+
+// number returns the index, calculated for the element
+func (k *pathKey) number(element *Node, Default float64) (result float64, err error) {
+	var integer int
+	if k.value == "" {
+		result = Default
+	} else if k.value == "(@.length)" {
+		result = float64(element.Size())
+	} else if k.expr != nil || k.err != nil {
+		if k.err != nil {
+			return 0, k.err
+		}
+		var temp *Node
+		temp, err = k.expr.Evaluate(element)
+		if err != nil {
+			return
+		}
+		integer, err = temp.getInteger()
+		if err != nil {
+			return
+		}
+		result = float64(integer)
+	} else {
+		integer, err = strconv.Atoi(k.value)
+		if err != nil {
+			return 0, errorRequest("wrong index '%s'", k.value)
+		}
+		result = float64(integer)
+	}
+	return
+}
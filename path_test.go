@@ -0,0 +1,255 @@
+package ajson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	tests := []string{
+		"$",
+		"@",
+		"$.store.book[*].author",
+		"$..author",
+		"$.store.*",
+		"$.store..price",
+		"$..book[2]",
+		"$..book[-1:]",
+		"$..book[(@.length-1)]",
+		"$..book[0,1]",
+		"$..book[:2]",
+		"$..book[::-1]",
+		"$..book[?(@.isbn)]",
+		"$..book[?(@.price<10)]",
+		"$..book[?(@.price > $.expensive)].title",
+		"$..*",
+		"$..book.length",
+		"$['store']['bicycle']['color']",
+		"$..['price','color']",
+		"$.store.book[(@.length-1):]",
+		"$.store.book[(true)]",
+		"$.unknown",
+	}
+	root := Must(Unmarshal(jsonExample))
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			expected, err := root.JSONPath(path)
+			if err != nil {
+				t.Fatalf("JSONPath() error = %v", err)
+			}
+			compiled, err := CompileJSONPath(path)
+			if err != nil {
+				t.Fatalf("CompileJSONPath() error = %v", err)
+			}
+			if compiled.String() != path {
+				t.Errorf("String() = %s, expected %s", compiled.String(), path)
+			}
+			for i := 0; i < 2; i++ {
+				result, err := compiled.Evaluate(root)
+				if err != nil {
+					t.Fatalf("Evaluate() error = %v", err)
+				}
+				if !reflect.DeepEqual(Paths(result), Paths(expected)) {
+					t.Errorf("Evaluate() = %v, expected %v", Paths(result), Paths(expected))
+				}
+			}
+		})
+	}
+}
+
+func TestCompileJSONPath_error(t *testing.T) {
+	tests := []string{
+		"",
+		"$[1",
+		"$.foo[1:2:3:4:5]",
+		"$[?(@.price ~ 10)]",
+		"$[(@.length - ()]",
+		"$['foo",
+		"x",
+	}
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			if _, err := CompileJSONPath(path); err == nil {
+				t.Errorf("CompileJSONPath() expected error")
+			}
+		})
+	}
+}
+
+func TestPath_Evaluate_error(t *testing.T) {
+	compiled := MustCompileJSONPath("$[1:2:0]")
+	if result, err := compiled.Evaluate(Must(Unmarshal([]byte(`{}`)))); err != nil || len(result) != 0 {
+		t.Errorf("Evaluate() wrong result = %v, error = %v", result, err)
+	}
+	if _, err := compiled.Evaluate(Must(Unmarshal([]byte(`[1, 2]`)))); err == nil {
+		t.Errorf("Evaluate() expected error")
+	}
+}
+
+func TestPath_Evaluate_pathError(t *testing.T) {
+	failure := errors.New("custom failure")
+	AddFunction("test_path_error", func(node *Node) (result *Node, err error) {
+		return nil, failure
+	})
+	data := []byte(`{"items": [{"price": 1, "count": 2}], "list": [1, 2]}`)
+	tests := []struct {
+		name    string
+		path    string
+		segment int
+		command string
+		offset  int
+		node    string
+		cause   error
+	}{
+		{name: "filter", path: "$.items[?(@.price / 0 > 1)]", segment: 2, command: "?(@.price / 0 > 1)", offset: 8, node: "$['items'][0]"},
+		{name: "custom function", path: "$..items[?(test_path_error(@.count))]", segment: 3, command: "?(test_path_error(@.count))", offset: 9, node: "$['items'][0]", cause: failure},
+		{name: "slice step", path: "$.list[1:2:0]", segment: 2, command: "1:2:0", offset: 7, node: "$['list']"},
+		{name: "slice bound", path: "$.list[(@.missing):]", segment: 2, command: "(@.missing):", offset: 7, node: "$['list']"},
+		{name: "slice index", path: "$.list[1:x]", segment: 2, command: "1:x", offset: 7, node: "$['list']"},
+		{name: "script", path: "$['list'][(1 / 0)]", segment: 2, command: "(1 / 0)", offset: 10, node: "$['list']"},
+		{name: "union", path: "$.list[0,(1 / 0)]", segment: 2, command: "0,(1 / 0)", offset: 7, node: "$['list']"},
+		{name: "compilation", path: "$.items[?(@.price ~ 10)]", segment: 2, command: "?(@.price ~ 10)", offset: 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := JSONPath(data, test.path)
+			if err == nil {
+				t.Fatalf("JSONPath() expected error")
+			}
+			result, ok := err.(*PathError)
+			if !ok {
+				t.Fatalf("JSONPath() error = %v, expected PathError", err)
+			}
+			if result.Path != test.path || result.Segment != test.segment || result.Command != test.command || result.Offset != test.offset || result.Node != test.node {
+				t.Errorf("JSONPath() error = %#v", result)
+			}
+			if test.cause != nil && rootCause(err) != test.cause {
+				t.Errorf("JSONPath() error = %v, expected to wrap %v", err, test.cause)
+			}
+			if _, ok := rootCause(err).(Error); test.cause == nil && !ok {
+				t.Errorf("JSONPath() error = %v, expected to wrap Error", err)
+			}
+		})
+	}
+}
+
+// rootCause returns the last error in the chain of the wrapped errors
+func rootCause(err error) error {
+	for {
+		wrapped, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return err
+		}
+		err = wrapped.Unwrap()
+	}
+}
+
+func TestPathError_nested(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": [{"b": [1, 2]}]}`)))
+	_, err := Eval(root, "avg(@.a[?(@.b[1:2:0])])")
+	result, ok := err.(*PathError)
+	if !ok {
+		t.Fatalf("Eval() error = %v, expected PathError", err)
+	}
+	if result.Path != "@.a[?(@.b[1:2:0])]" || result.Node != "$['a'][0]" {
+		t.Errorf("Eval() error = %#v", result)
+	}
+	nested, ok := result.Unwrap().(*PathError)
+	if !ok {
+		t.Fatalf("Eval() error = %v, expected nested PathError", result.Err)
+	}
+	if nested.Path != "@.b[1:2:0]" || nested.Command != "1:2:0" || nested.Node != "$['a'][0]['b']" {
+		t.Errorf("Eval() nested error = %#v", nested)
+	}
+}
+
+func TestJSONPathReader_pathError(t *testing.T) {
+	err := JSONPathReader(bytes.NewReader([]byte(`{"items": [{"price": 1}]}`)), "$.items[?(@.price / 0)]", func(*Node) error {
+		return nil
+	})
+	result, ok := err.(*PathError)
+	if !ok {
+		t.Fatalf("JSONPathReader() error = %v, expected PathError", err)
+	}
+	if result.Segment != 2 || result.Offset != 8 || result.Node != "$['items'][0]" {
+		t.Errorf("JSONPathReader() error = %#v", result)
+	}
+}
+
+func TestPath_Evaluate_concurrent(t *testing.T) {
+	compiled := MustCompileJSONPath("$..book[?(@.price < 10)].title")
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root, err := Unmarshal(jsonExample)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for j := 0; j < 10; j++ {
+				result, err := compiled.Evaluate(root)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(result) != 2 {
+					errs <- fmt.Errorf("wrong result size: %d", len(result))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Evaluate() error = %v", err)
+	}
+}
+
+func TestMustCompileJSONPath(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompileJSONPath() expected panic")
+		}
+	}()
+	MustCompileJSONPath("$[1")
+}
+
+func BenchmarkPath_Evaluate_all_prices(b *testing.B) {
+	root := Must(Unmarshal(jsonPathTestData))
+	compiled := MustCompileJSONPath("$.store..price")
+	for i := 0; i < b.N; i++ {
+		if _, err := compiled.Evaluate(root); err != nil {
+			b.Error()
+		}
+	}
+}
+
+func ExampleCompileJSONPath() {
+	path := MustCompileJSONPath("$.items[?(@.price >= 10)].id")
+	documents := []string{
+		`{"items": [{"id": 1, "price": 10}, {"id": 2, "price": 5}]}`,
+		`{"items": [{"id": 3, "price": 25}, {"id": 4, "price": 15}]}`,
+	}
+	for _, document := range documents {
+		root := Must(Unmarshal([]byte(document)))
+		nodes, err := path.Evaluate(root)
+		if err != nil {
+			panic(err)
+		}
+		for _, node := range nodes {
+			fmt.Println(node.MustNumeric())
+		}
+	}
+	// Output:
+	// 1
+	// 3
+	// 4
+}
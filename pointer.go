@@ -0,0 +1,146 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	pointerEscape   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescape = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Pointer returns JSON Pointer (RFC 6901) of the current node from the root, e.g.: `/store/book/0/title`.
+// Pointer of the root node is an empty string.
+func (n *Node) Pointer() string {
+	if n.parent == nil {
+		return ""
+	}
+	if n.key != nil {
+		return n.parent.Pointer() + "/" + pointerEscape.Replace(n.Key())
+	}
+	return n.parent.Pointer() + "/" + strconv.Itoa(n.Index())
+}
+
+// ParseJSONPointer will parse JSON Pointer and return all its reference tokens, already unescaped.
+// Example:
+//
+//	result, _ := ParseJSONPointer("/store/book/0/a~1b")
+//	result == []string{"store", "book", "0", "a/b"}
+func ParseJSONPointer(pointer string) (result []string, err error) {
+	result = make([]string, 0)
+	if pointer == "" {
+		return result, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errorRequest("JSON Pointer should start with '/', got '%s'", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' && (i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+				return nil, errorRequest("wrong escape sequence in JSON Pointer '%s'", pointer)
+			}
+		}
+		result = append(result, pointerUnescape.Replace(token))
+	}
+	return result, nil
+}
+
+// GetPointer returns the node by its JSON Pointer (RFC 6901), relative to the current node.
+func (n *Node) GetPointer(pointer string) (*Node, error) {
+	tokens, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return n.getPointer(tokens)
+}
+
+// SetPointer sets the value by JSON Pointer (RFC 6901), relative to the current node.
+// Existing node will be replaced, new key will be added to an Object, and the token `-` (or the index equal to the size)
+// will append the value to an Array.
+func (n *Node) SetPointer(pointer string, value *Node) error {
+	tokens, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errorRequest("can't set the node itself")
+	}
+	parent, err := n.getPointer(tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.Type() {
+	case Object:
+		return parent.AppendObject(token, value)
+	case Array:
+		index, err := pointerIndex(parent, token)
+		if err != nil {
+			return err
+		}
+		if index == parent.Size() {
+			return parent.AppendArray(value)
+		}
+		return parent.replace(parent.children[token], value)
+	}
+	return errorType()
+}
+
+// DeletePointer removes the node by its JSON Pointer (RFC 6901), relative to the current node.
+func (n *Node) DeletePointer(pointer string) error {
+	tokens, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errorRequest("can't delete the node itself")
+	}
+	node, err := n.getPointer(tokens)
+	if err != nil {
+		return err
+	}
+	return node.Delete()
+}
+
+func (n *Node) getPointer(tokens []string) (node *Node, err error) {
+	node = n
+	for _, token := range tokens {
+		switch node.Type() {
+		case Object:
+			node, err = node.GetKey(token)
+		case Array:
+			var index int
+			if index, err = pointerIndex(node, token); err != nil {
+				return nil, err
+			}
+			node, err = node.GetIndex(index)
+		default:
+			return nil, errorRequest("wrong pointer token '%s' for the scalar node", token)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// pointerIndex returns the index of an Array by the token: `-` means the size of the Array, leading zeros are not allowed
+func pointerIndex(node *Node, token string) (int, error) {
+	if token == "-" {
+		return node.Size(), nil
+	}
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return 0, errorRequest("wrong index '%s'", token)
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, errorRequest("wrong index '%s'", token)
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index > node.Size() {
+		return 0, errorRequest("out of index '%s'", token)
+	}
+	return index, nil
+}
@@ -0,0 +1,232 @@
+package ajson
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// RFC 6901 section 5
+var pointerExample = []byte(`{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`)
+
+func TestNode_GetPointer(t *testing.T) {
+	root := Must(Unmarshal(pointerExample))
+	tests := []struct {
+		pointer  string
+		expected string
+	}{
+		{pointer: ``, expected: string(pointerExample)},
+		{pointer: `/foo`, expected: `["bar", "baz"]`},
+		{pointer: `/foo/0`, expected: `"bar"`},
+		{pointer: `/foo/1`, expected: `"baz"`},
+		{pointer: `/`, expected: `0`},
+		{pointer: `/a~1b`, expected: `1`},
+		{pointer: `/c%d`, expected: `2`},
+		{pointer: `/e^f`, expected: `3`},
+		{pointer: `/g|h`, expected: `4`},
+		{pointer: `/i\j`, expected: `5`},
+		{pointer: `/k"l`, expected: `6`},
+		{pointer: `/ `, expected: `7`},
+		{pointer: `/m~0n`, expected: `8`},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			node, err := root.GetPointer(test.pointer)
+			if err != nil {
+				t.Fatalf("GetPointer() error = %v", err)
+			}
+			if string(node.Source()) != test.expected {
+				t.Errorf("GetPointer() = %s, expected %s", node.Source(), test.expected)
+			}
+			if node.Pointer() != test.pointer {
+				t.Errorf("Pointer() = %s, expected %s", node.Pointer(), test.pointer)
+			}
+		})
+	}
+}
+
+func TestNode_GetPointer_error(t *testing.T) {
+	root := Must(Unmarshal(pointerExample))
+	tests := []string{
+		`foo`,
+		`/unknown`,
+		`/foo/2`,
+		`/foo/-`,
+		`/foo/-1`,
+		`/foo/01`,
+		`/foo/a`,
+		`/foo/0/bar`,
+		`/m~2n`,
+		`/m~`,
+	}
+	for _, pointer := range tests {
+		t.Run(pointer, func(t *testing.T) {
+			if _, err := root.GetPointer(pointer); err == nil {
+				t.Errorf("GetPointer() expected error")
+			}
+		})
+	}
+}
+
+func TestParseJSONPointer(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		expected []string
+	}{
+		{pointer: ``, expected: []string{}},
+		{pointer: `/`, expected: []string{""}},
+		{pointer: `//`, expected: []string{"", ""}},
+		{pointer: `/store/book/0/title`, expected: []string{"store", "book", "0", "title"}},
+		{pointer: `/a~1b/m~0n/~01`, expected: []string{"a/b", "m~n", "~1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			result, err := ParseJSONPointer(test.pointer)
+			if err != nil {
+				t.Fatalf("ParseJSONPointer() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("ParseJSONPointer() = %v, expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_SetPointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pointer  string
+		value    *Node
+		expected string
+	}{
+		{name: "replace key", input: `{"a":{"b":1}}`, pointer: `/a/b`, value: NumericNode("", 2), expected: `{"a":{"b":2}}`},
+		{name: "add key", input: `{"a":{}}`, pointer: `/a/c`, value: StringNode("", "x"), expected: `{"a":{"c":"x"}}`},
+		{name: "escaped key", input: `{}`, pointer: `/a~1b~0c`, value: NullNode(""), expected: `{"a/b~c":null}`},
+		{name: "replace index", input: `[1,2,3]`, pointer: `/1`, value: BoolNode("", true), expected: `[1,true,3]`},
+		{name: "append", input: `[1,2,3]`, pointer: `/-`, value: NumericNode("", 4), expected: `[1,2,3,4]`},
+		{name: "append by size", input: `{"a":[]}`, pointer: `/a/0`, value: NumericNode("", 1), expected: `{"a":[1]}`},
+		{name: "container", input: `{"a":[]}`, pointer: `/a/-`, value: ObjectNode("", map[string]*Node{"b": NumericNode("", 1)}), expected: `{"a":[{"b":1}]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := root.SetPointer(test.pointer, test.value); err != nil {
+				t.Fatalf("SetPointer() error = %v", err)
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("SetPointer() = %s, expected %s", result, test.expected)
+			}
+			if test.value.Pointer() != test.pointer && test.pointer != "/-" && test.pointer != "/a/-" {
+				t.Errorf("Pointer() = %s, expected %s", test.value.Pointer(), test.pointer)
+			}
+		})
+	}
+}
+
+func TestNode_SetPointer_move(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1,2,3]`)))
+	if err := root.SetPointer("/2", root.MustIndex(0)); err != nil {
+		t.Fatalf("SetPointer() error = %v", err)
+	}
+	result, err := Marshal(root)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(result) != `[2,1]` {
+		t.Errorf("SetPointer() = %s, expected [2,1]", result)
+	}
+}
+
+func TestNode_SetPointer_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pointer string
+	}{
+		{name: "root", input: `{}`, pointer: ``},
+		{name: "wrong pointer", input: `{}`, pointer: `a`},
+		{name: "no parent", input: `{}`, pointer: `/a/b`},
+		{name: "out of index", input: `[1]`, pointer: `/2`},
+		{name: "wrong index", input: `[1]`, pointer: `/a`},
+		{name: "scalar", input: `{"a":1}`, pointer: `/a/b`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := root.SetPointer(test.pointer, NullNode("")); err == nil {
+				t.Errorf("SetPointer() expected error")
+			}
+		})
+	}
+	root := Must(Unmarshal([]byte(`{"a":{"b":[]}}`)))
+	if err := root.SetPointer("/a/b/-", root.MustKey("a")); err == nil {
+		t.Errorf("SetPointer() expected error on loop")
+	}
+}
+
+func TestNode_DeletePointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		pointer  string
+		expected string
+	}{
+		{name: "key", input: `{"a":{"b":1,"c":2}}`, pointer: `/a/b`, expected: `{"a":{"c":2}}`},
+		{name: "index", input: `{"a":[1,2,3]}`, pointer: `/a/0`, expected: `{"a":[2,3]}`},
+		{name: "escaped", input: `{"a/b":1,"c":2}`, pointer: `/a~1b`, expected: `{"c":2}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := root.DeletePointer(test.pointer); err != nil {
+				t.Fatalf("DeletePointer() error = %v", err)
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("DeletePointer() = %s, expected %s", result, test.expected)
+			}
+		})
+	}
+	root := Must(Unmarshal([]byte(`[1]`)))
+	for _, pointer := range []string{``, `/-`, `/1`, `/a`} {
+		if err := root.DeletePointer(pointer); err == nil {
+			t.Errorf("DeletePointer(%q) expected error", pointer)
+		}
+	}
+}
+
+func ExampleNode_GetPointer() {
+	root := Must(Unmarshal(jsonExample))
+	node, err := root.GetPointer("/store/book/2/title")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s: %s\n", node.Path(), node.MustString())
+	nodes, err := root.JSONPath("$..bicycle.color")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(nodes[0].Pointer())
+	// Output:
+	// $['store']['book'][2]['title']: Moby Dick
+	// /store/bicycle/color
+}
@@ -1,6 +1,9 @@
 package ajson
 
-import "unicode/utf8"
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
 // This file was copied from encoding/json library.
 // fixme: https://github.com/spyzhov/ajson/issues/13
@@ -220,7 +223,7 @@ var htmlSafeSet = [utf8.RuneSelf]bool{
 	'\u007f': true,
 }
 
-func quoteString(s string, escapeHTML bool) []byte {
+func quoteString(s string, escapeHTML bool, ascii bool) []byte {
 	result := make([]byte, 0, len(s))
 	start := 0
 	for i := 0; i < len(s); {
@@ -287,6 +290,20 @@ func quoteString(s string, escapeHTML bool) []byte {
 			start = i
 			continue
 		}
+		// All non-ASCII characters are escaped as \uXXXX, characters out of the BMP as the UTF-16 surrogate pair.
+		if ascii {
+			if start < i {
+				result = append(result, s[start:i]...)
+			}
+			if r1, r2 := utf16.EncodeRune(c); r1 != utf8.RuneError {
+				result = appendRune(appendRune(result, r1), r2)
+			} else {
+				result = appendRune(result, c)
+			}
+			i += size
+			start = i
+			continue
+		}
 		i += size
 	}
 	if start < len(s) {
@@ -294,3 +311,8 @@ func quoteString(s string, escapeHTML bool) []byte {
 	}
 	return result
 }
+
+// appendRune appends the \uXXXX escape sequence of the rune from the BMP
+func appendRune(result []byte, r rune) []byte {
+	return append(result, '\\', 'u', hex[r>>12&0xF], hex[r>>8&0xF], hex[r>>4&0xF], hex[r&0xF])
+}
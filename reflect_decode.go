@@ -0,0 +1,550 @@
+package ajson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	nodeType            = reflect.TypeOf((*Node)(nil))
+	numberType          = reflect.TypeOf(json.Number(""))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decode stores the value of the current node into the value pointed by v, same as json.Unmarshal does.
+//
+// Decode follows the rules of encoding/json: it fills structs, slices, arrays, maps, pointers and interfaces,
+// honours `json:"name,omitempty,string"` tags and embedded structs, and calls json.Unmarshaler and
+// encoding.TextUnmarshaler implementations. Fields of the type *Node get the copy of the corresponding node.
+// Values are taken directly from the nodes, so integers keep all their digits.
+//
+// Example:
+//
+//	nodes, _ := root.JSONPath("$.store.book[?(@.price < 10)]")
+//	books := make([]Book, len(nodes))
+//	for i, node := range nodes {
+//		if err := node.Decode(&books[i]); err != nil {
+//			return err
+//		}
+//	}
+func (n *Node) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errorRequest("Decode requires a non-nil pointer, got %T", v)
+	}
+	return decodeValue(n, rv)
+}
+
+// field is the description of the struct field, for the encoding and decoding
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+// structFields is the list of fields of the struct, which are visible for JSON
+type structFields struct {
+	list  []field
+	names map[string]int
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+func decodeValue(node *Node, v reflect.Value) error {
+	if v.Type() == nodeType && v.CanSet() {
+		v.Set(reflect.ValueOf(node.Clone()))
+		return nil
+	}
+	null := node.IsNull()
+	unmarshaler, textUnmarshaler, v := indirect(v, null)
+	if unmarshaler != nil {
+		data, err := Marshal(node)
+		if err != nil {
+			return err
+		}
+		return unmarshaler.UnmarshalJSON(data)
+	}
+	if textUnmarshaler != nil {
+		if !node.IsString() {
+			return errorDecode(node, reflect.TypeOf(textUnmarshaler))
+		}
+		value, err := node.GetString()
+		if err != nil {
+			return err
+		}
+		return textUnmarshaler.UnmarshalText([]byte(value))
+	}
+	if v.Type() == nodeType.Elem() {
+		v.Set(reflect.ValueOf(node.Clone()).Elem())
+		return nil
+	}
+	if null {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.Unpack()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(value))
+	case reflect.Bool:
+		if !node.IsBool() {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.GetBool()
+		if err != nil {
+			return err
+		}
+		v.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !node.IsNumeric() {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.GetInt64()
+		if err != nil || v.OverflowInt(value) {
+			return errorDecode(node, v.Type())
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !node.IsNumeric() {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.GetUint64()
+		if err != nil || v.OverflowUint(value) {
+			return errorDecode(node, v.Type())
+		}
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		if !node.IsNumeric() {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.GetNumeric()
+		if err != nil {
+			return err
+		}
+		if v.OverflowFloat(value) {
+			return errorDecode(node, v.Type())
+		}
+		v.SetFloat(value)
+	case reflect.String:
+		if v.Type() == numberType {
+			// json.Number accepts numbers and strings with the valid number inside
+			number := node
+			if node.IsString() {
+				value, err := node.GetString()
+				if err != nil {
+					return err
+				}
+				if number, err = NumberNode("", value); err != nil {
+					return errorDecode(node, v.Type())
+				}
+			}
+			value, err := number.GetNumberString()
+			if err != nil {
+				return errorDecode(node, v.Type())
+			}
+			v.SetString(value)
+			return nil
+		}
+		if !node.IsString() {
+			return errorDecode(node, v.Type())
+		}
+		value, err := node.GetString()
+		if err != nil {
+			return err
+		}
+		v.SetString(value)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && node.IsString() {
+			value, err := node.GetString()
+			if err != nil {
+				return err
+			}
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return errorRequest("can't decode base64 value at %s: %s", node.Path(), err)
+			}
+			v.SetBytes(data)
+			return nil
+		}
+		if !node.IsArray() {
+			return errorDecode(node, v.Type())
+		}
+		elements := node.Inheritors()
+		slice := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := decodeValue(element, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		if !node.IsArray() {
+			return errorDecode(node, v.Type())
+		}
+		elements := node.Inheritors()
+		for i := 0; i < v.Len(); i++ {
+			if i < len(elements) {
+				if err := decodeValue(elements[i], v.Index(i)); err != nil {
+					return err
+				}
+			} else {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		}
+	case reflect.Map:
+		if !node.IsObject() {
+			return errorDecode(node, v.Type())
+		}
+		return decodeMap(node, v)
+	case reflect.Struct:
+		if !node.IsObject() {
+			return errorDecode(node, v.Type())
+		}
+		return decodeStruct(node, v)
+	default:
+		return errorRequest("can't decode into unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func decodeMap(node *Node, v reflect.Value) error {
+	kt := v.Type().Key()
+	switch kt.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+			return errorRequest("can't decode into map with key type %s", kt)
+		}
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for _, key := range node.Keys() {
+		element := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(node.children[key], element); err != nil {
+			return err
+		}
+		var kv reflect.Value
+		if reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+			kv = reflect.New(kt)
+			if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+				return err
+			}
+			kv = kv.Elem()
+		} else {
+			switch kt.Kind() {
+			case reflect.String:
+				kv = reflect.ValueOf(key).Convert(kt)
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				value, err := strconv.ParseInt(key, 10, 64)
+				if err != nil || reflect.Zero(kt).OverflowInt(value) {
+					return errorRequest("can't decode key '%s' into %s", key, kt)
+				}
+				kv = reflect.ValueOf(value).Convert(kt)
+			default:
+				value, err := strconv.ParseUint(key, 10, 64)
+				if err != nil || reflect.Zero(kt).OverflowUint(value) {
+					return errorRequest("can't decode key '%s' into %s", key, kt)
+				}
+				kv = reflect.ValueOf(value).Convert(kt)
+			}
+		}
+		v.SetMapIndex(kv, element)
+	}
+	return nil
+}
+
+func decodeStruct(node *Node, v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	for _, key := range node.Keys() {
+		index, ok := fields.names[key]
+		if !ok {
+			index = -1
+			for i := range fields.list {
+				if strings.EqualFold(fields.list[i].name, key) {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				continue
+			}
+		}
+		f := &fields.list[index]
+		value, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return err
+		}
+		child := node.children[key]
+		if f.quoted && !child.IsNull() {
+			if !child.IsString() {
+				return errorDecode(child, f.typ)
+			}
+			str, err := child.GetString()
+			if err != nil {
+				return err
+			}
+			if child, err = Unmarshal([]byte(str)); err != nil {
+				return errorRequest("can't decode quoted value '%s' at %s", str, node.children[key].Path())
+			}
+		}
+		if err = decodeValue(child, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the field of the struct, allocating nil pointers to embedded structs
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, errorRequest("can't set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// indirect walks down v allocating pointers as needed, until it gets to a non-pointer.
+// If it encounters an Unmarshaler, indirect stops and returns that.
+// If null is true, indirect stops at the first settable pointer, so it can be set to nil.
+func indirect(v reflect.Value, null bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	// pointer methods should be found for the addressable named values
+	if v.Kind() != reflect.Ptr && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			element := v.Elem()
+			if element.Kind() == reflect.Ptr && !element.IsNil() && (!null || element.Elem().Kind() == reflect.Ptr) {
+				v = element
+				continue
+			}
+		}
+		if v.Kind() != reflect.Ptr {
+			break
+		}
+		if null && v.CanSet() {
+			break
+		}
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem() == v {
+			v = v.Elem()
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !null {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+		}
+		v = v.Elem()
+	}
+	return nil, nil, v
+}
+
+// cachedFields returns fields of the struct type, by the rules of encoding/json
+func cachedFields(t reflect.Type) *structFields {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(*structFields)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.(*structFields)
+}
+
+// typeFields returns the list of fields, that JSON should recognize for the given type.
+// The algorithm is breadth-first search over the set of structs to include: the top struct and then any reachable
+// anonymous structs. Fields with the same name are resolved by the depth, and then by the presence of the tag.
+func typeFields(t reflect.Type) *structFields {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+	var (
+		current []queued
+		next    = []queued{{typ: t}}
+		visited = map[reflect.Type]bool{}
+		list    []field
+	)
+	for len(next) > 0 {
+		current, next = next, nil
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue // unexported non-struct embedded field
+					}
+				} else if sf.PkgPath != "" {
+					continue // unexported field
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := parseTag(tag)
+				index := make([]int, len(q.index)+1)
+				copy(index, q.index)
+				index[len(q.index)] = i
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, queued{typ: ft, index: index})
+					continue
+				}
+				f := field{
+					name:      name,
+					index:     index,
+					typ:       sf.Type,
+					tagged:    name != "",
+					omitEmpty: options.contains("omitempty"),
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				if options.contains("string") {
+					switch ft.Kind() {
+					case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64, reflect.String:
+						f.quoted = true
+					}
+				}
+				list = append(list, f)
+			}
+		}
+	}
+
+	// fields are ordered as they are declared, with embedded fields in place of their struct
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].index, list[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	byName := make(map[string][]int, len(list))
+	for i, f := range list {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	result := &structFields{names: make(map[string]int, len(list))}
+	for i, f := range list {
+		if dominant, ok := dominantField(list, byName[f.name]); !ok || dominant != i {
+			continue
+		}
+		result.names[f.name] = len(result.list)
+		result.list = append(result.list, f)
+	}
+	return result
+}
+
+// dominantField returns the index of the field, which hides other fields with the same name: the shallowest one,
+// or the only tagged one of the shallowest. Returns false if there is no such field.
+func dominantField(list []field, indexes []int) (int, bool) {
+	depth := len(list[indexes[0]].index)
+	for _, i := range indexes {
+		if len(list[i].index) < depth {
+			depth = len(list[i].index)
+		}
+	}
+	var found []int
+	for _, i := range indexes {
+		if len(list[i].index) == depth {
+			found = append(found, i)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], true
+	}
+	dominant, tagged := -1, 0
+	for _, i := range found {
+		if list[i].tagged {
+			dominant = i
+			tagged++
+		}
+	}
+	return dominant, tagged == 1
+}
+
+// tagOptions is the string following a comma in a struct field's "json" tag
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	if index := strings.IndexByte(tag, ','); index != -1 {
+		return tag[:index], tagOptions(tag[index+1:])
+	}
+	return tag, ""
+}
+
+func (o tagOptions) contains(name string) bool {
+	for _, option := range strings.Split(string(o), ",") {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the name of the NodeType
+func typeName(t NodeType) string {
+	switch t {
+	case Null:
+		return "null"
+	case Numeric:
+		return "number"
+	case String:
+		return "string"
+	case Bool:
+		return "bool"
+	case Array:
+		return "array"
+	case Object:
+		return "object"
+	}
+	return "unknown"
+}
+
+func errorDecode(node *Node, t reflect.Type) error {
+	return errorRequest("can't decode %s value at %s into %s", typeName(node.Type()), node.Path(), t)
+}
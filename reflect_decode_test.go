@@ -0,0 +1,305 @@
+package ajson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type decodeBase struct {
+	ID      int64  `json:"id"`
+	Created string `json:"created,omitempty"`
+}
+
+type decodeMeta struct {
+	Tags []string `json:"tags"`
+}
+
+type decodeItem struct {
+	decodeBase
+	decodeMeta
+	Name     string          `json:"name"`
+	Price    float64         `json:"price,string"`
+	Count    *int            `json:"count"`
+	Enabled  bool            `json:"enabled,string"`
+	Skipped  string          `json:"-"`
+	Extra    map[string]int  `json:"extra"`
+	Sizes    [2]int          `json:"sizes"`
+	Any      interface{}     `json:"any"`
+	Raw      *Node           `json:"raw"`
+	Number   json.Number     `json:"number"`
+	Time     time.Time       `json:"time"`
+	Upper    decodeUpper     `json:"upper"`
+	Data     []byte          `json:"data"`
+	Children []decodeItem    `json:"children"`
+	Labels   map[int]string  `json:"labels"`
+	Message  json.RawMessage `json:"message"`
+	Default  string
+	private  string
+}
+
+type decodeUpper string
+
+func (u *decodeUpper) UnmarshalText(text []byte) error {
+	*u = decodeUpper(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestNode_Decode(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{
+		"id": 9007199254740993,
+		"created": "today",
+		"tags": ["a", "b"],
+		"name": "item",
+		"price": "1.25",
+		"count": 3,
+		"enabled": "true",
+		"Skipped": "no",
+		"extra": {"x": 1, "y": 2},
+		"sizes": [5],
+		"any": {"k": [1, "2", null, true]},
+		"raw": {"keep": [1.50]},
+		"number": 1.50,
+		"time": "2020-01-02T03:04:05Z",
+		"upper": "abc",
+		"data": "aGVsbG8=",
+		"children": [{"name": "child", "count": null}],
+		"labels": {"1": "one", "-2": "minus two"},
+		"message": {"a" : 1},
+		"default": "case insensitive",
+		"private": "no",
+		"unknown": "ignored"
+	}`)))
+	var item decodeItem
+	item.Sizes = [2]int{1, 2}
+	if err := root.Decode(&item); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	count := 3
+	expected := decodeItem{
+		decodeBase: decodeBase{ID: 9007199254740993, Created: "today"},
+		decodeMeta: decodeMeta{Tags: []string{"a", "b"}},
+		Name:       "item",
+		Price:      1.25,
+		Count:      &count,
+		Enabled:    true,
+		Extra:      map[string]int{"x": 1, "y": 2},
+		Sizes:      [2]int{5, 0},
+		Any:        map[string]interface{}{"k": []interface{}{float64(1), "2", nil, true}},
+		Number:     "1.50",
+		Time:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Upper:      "ABC",
+		Data:       []byte("hello"),
+		Children:   []decodeItem{{Name: "child"}},
+		Labels:     map[int]string{1: "one", -2: "minus two"},
+		Message:    json.RawMessage(`{"a" : 1}`),
+		Default:    "case insensitive",
+	}
+	if item.Raw == nil || marshalString(item.Raw) != `{"keep": [1.50]}` {
+		t.Errorf("Decode() wrong raw node")
+	} else if item.Raw.Parent() != nil {
+		t.Errorf("Decode() raw node should be detached")
+	}
+	item.Raw = nil
+	if !reflect.DeepEqual(item, expected) {
+		t.Errorf("Decode() = %+v\nexpected %+v", item, expected)
+	}
+}
+
+func TestNode_Decode_values(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   interface{}
+		expected interface{}
+	}{
+		{input: `true`, target: new(bool), expected: true},
+		{input: `-128`, target: new(int8), expected: int8(-128)},
+		{input: `255`, target: new(uint8), expected: uint8(255)},
+		{input: `18446744073709551615`, target: new(uint64), expected: uint64(18446744073709551615)},
+		{input: `1e2`, target: new(int), expected: 100},
+		{input: `1.5`, target: new(float32), expected: float32(1.5)},
+		{input: `"text"`, target: new(string), expected: "text"},
+		{input: `[1, 2, 3]`, target: new([]int), expected: []int{1, 2, 3}},
+		{input: `[]`, target: new([]int), expected: []int{}},
+		{input: `null`, target: new([]int), expected: []int(nil)},
+		{input: `[[1], [2, 3]]`, target: new([][]uint), expected: [][]uint{{1}, {2, 3}}},
+		{input: `{"a": {"b": "c"}}`, target: new(map[string]map[string]string), expected: map[string]map[string]string{"a": {"b": "c"}}},
+		{input: `{"a": null}`, target: new(map[string]*int), expected: map[string]*int{"a": nil}},
+		{input: `"2"`, target: new(interface{}), expected: "2"},
+		{input: `12345678901234567890`, target: new(json.Number), expected: json.Number("12345678901234567890")},
+		{input: `"1e2"`, target: new(json.Number), expected: json.Number("1e2")},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if err := Must(Unmarshal([]byte(test.input))).Decode(test.target); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if result := reflect.ValueOf(test.target).Elem().Interface(); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Decode() = %#v, expected %#v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_Decode_null(t *testing.T) {
+	value := 5
+	target := struct {
+		Pointer *int
+		Value   int
+		Slice   []int
+		Map     map[string]int
+	}{Pointer: &value, Value: 1, Slice: []int{1}, Map: map[string]int{}}
+	root := Must(Unmarshal([]byte(`{"Pointer": null, "Value": null, "Slice": null, "Map": null}`)))
+	if err := root.Decode(&target); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if target.Pointer != nil || target.Value != 1 || target.Slice != nil || target.Map != nil {
+		t.Errorf("Decode() = %+v", target)
+	}
+	if value != 5 {
+		t.Errorf("Decode() changed the value by pointer")
+	}
+}
+
+func TestNode_Decode_embedded(t *testing.T) {
+	type A struct {
+		Name  string
+		Value int `json:"value"`
+	}
+	type B struct {
+		Name  string
+		Value int
+	}
+	type D struct {
+		Extra string
+	}
+	type C struct {
+		A
+		B
+		*D
+		Name string `json:"title"`
+	}
+	var target C
+	root := Must(Unmarshal([]byte(`{"Name": "name", "value": 1, "title": "title", "Extra": "extra"}`)))
+	if err := root.Decode(&target); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	// `Name` is ambiguous, and the tagged `value` of A dominates the untagged `Value` of B
+	expected := C{A: A{Value: 1}, D: &D{Extra: "extra"}, Name: "title"}
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("Decode() = %+v, expected %+v", target, expected)
+	}
+}
+
+func TestNode_Decode_unmarshaler(t *testing.T) {
+	var target struct {
+		Message json.RawMessage
+		Time    *time.Time
+	}
+	root := Must(Unmarshal([]byte(`{"Message": [1, {"a": "b"}], "Time": "2021-06-07T08:09:10Z"}`)))
+	if err := root.Decode(&target); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if string(target.Message) != `[1, {"a": "b"}]` {
+		t.Errorf("Decode() = %s", target.Message)
+	}
+	if target.Time == nil || !target.Time.Equal(time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)) {
+		t.Errorf("Decode() = %v", target.Time)
+	}
+	if err := Must(Unmarshal([]byte(`{"Time": "yesterday"}`))).Decode(&target); err == nil {
+		t.Errorf("Decode() expected error")
+	}
+}
+
+func TestNode_Decode_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		target interface{}
+	}{
+		{name: "nil", input: `1`, target: nil},
+		{name: "not pointer", input: `1`, target: 1},
+		{name: "nil pointer", input: `1`, target: (*int)(nil)},
+		{name: "string to int", input: `"1"`, target: new(int)},
+		{name: "float to int", input: `1.5`, target: new(int)},
+		{name: "overflow int8", input: `128`, target: new(int8)},
+		{name: "negative uint", input: `-1`, target: new(uint)},
+		{name: "overflow float32", input: `1e39`, target: new(float32)},
+		{name: "number to bool", input: `1`, target: new(bool)},
+		{name: "number to string", input: `1`, target: new(string)},
+		{name: "string to number", input: `"x"`, target: new(json.Number)},
+		{name: "embedded pointer", input: `{"tags": []}`, target: new(struct{ *decodeMeta })},
+		{name: "object to slice", input: `{}`, target: new([]int)},
+		{name: "array to map", input: `[]`, target: new(map[string]int)},
+		{name: "array to struct", input: `[]`, target: new(struct{})},
+		{name: "wrong base64", input: `"!"`, target: new([]byte)},
+		{name: "wrong map key", input: `{"a": 1}`, target: new(map[int]int)},
+		{name: "unsupported key", input: `{"a": 1}`, target: new(map[float64]int)},
+		{name: "unsupported type", input: `1`, target: new(chan int)},
+		{name: "not empty interface", input: `1`, target: new(fmt.Stringer)},
+		{name: "text unmarshaler", input: `1`, target: new(decodeUpper)},
+		{name: "quoted number", input: `{"price": 1.25}`, target: new(decodeItem)},
+		{name: "wrong quoted", input: `{"price": "x"}`, target: new(decodeItem)},
+		{name: "nested", input: `{"children": [{"name": 1}]}`, target: new(decodeItem)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Must(Unmarshal([]byte(test.input))).Decode(test.target); err == nil {
+				t.Errorf("Decode() expected error")
+			}
+		})
+	}
+	err := Must(Unmarshal([]byte(`{"a": [1, "2"]}`))).Decode(&map[string][]int{})
+	if err == nil || !strings.Contains(err.Error(), "$['a'][1]") {
+		t.Errorf("Decode() error = %v, expected the path of the node", err)
+	}
+}
+
+func TestNode_Decode_dirty(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": [1]}`)))
+	if err := root.MustKey("a").AppendArray(NumericNode("", 2), Int64Node("", 9007199254740993)); err != nil {
+		t.Fatalf("AppendArray() error = %v", err)
+	}
+	if err := root.AppendObject("b", StringNode("", "c")); err != nil {
+		t.Fatalf("AppendObject() error = %v", err)
+	}
+	var target struct {
+		A []int64
+		B string
+	}
+	if err := root.Decode(&target); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(target.A, []int64{1, 2, 9007199254740993}) || target.B != "c" {
+		t.Errorf("Decode() = %+v", target)
+	}
+}
+
+func ExampleNode_Decode() {
+	type Book struct {
+		Title  string  `json:"title"`
+		Author string  `json:"author"`
+		Price  float64 `json:"price"`
+	}
+	root := Must(Unmarshal([]byte(`{"store": {"book": [
+		{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+		{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+		{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "price": 8.99}
+	]}}`)))
+	nodes, err := root.JSONPath("$.store.book[?(@.price < 10)]")
+	if err != nil {
+		panic(err)
+	}
+	books := make([]Book, len(nodes))
+	for i, node := range nodes {
+		if err = node.Decode(&books[i]); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("%+v\n", books)
+	// Output:
+	// [{Title:Sayings of the Century Author:Nigel Rees Price:8.95} {Title:Moby Dick Author:Herman Melville Price:8.99}]
+}
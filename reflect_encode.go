@@ -0,0 +1,279 @@
+package ajson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ValueOf returns the Node tree built from the Go value, same as json.Marshal would encode it.
+//
+// ValueOf follows the rules of encoding/json: it walks structs, maps, slices, arrays, pointers and interfaces,
+// honours `json:"name,omitempty,string"` tags and embedded structs, and calls json.Marshaler and
+// encoding.TextMarshaler implementations, e.g. for time.Time. Values of the type *Node are copied.
+// The result is the changed (dirty) tree, which can be mutated and queried right away.
+//
+// Example:
+//
+//	root, err := ajson.ValueOf(map[string]interface{}{"ids": []int64{1, 2}, "created": time.Now()})
+//	if err != nil {
+//		return err
+//	}
+//	_ = root.MustKey("ids").AppendArray(ajson.Int64Node("", 3))
+func ValueOf(v interface{}) (*Node, error) {
+	node, err := valueOf("", reflect.ValueOf(v), make(map[uintptr]bool))
+	if err != nil {
+		return nil, err
+	}
+	node.key = nil
+	return node, nil
+}
+
+func valueOf(key string, v reflect.Value, visited map[uintptr]bool) (*Node, error) {
+	if !v.IsValid() {
+		return NullNode(key), nil
+	}
+	if v.Type() == nodeType {
+		if v.IsNil() {
+			return NullNode(key), nil
+		}
+		node := v.Interface().(*Node).Clone()
+		node.key = &key
+		return node, nil
+	}
+	if v.Type() == nodeType.Elem() {
+		node := reflect.New(nodeType.Elem())
+		node.Elem().Set(v)
+		return valueOf(key, node, visited)
+	}
+	if v.Type().Implements(marshalerType) {
+		return marshalerNode(key, v)
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType) {
+		return marshalerNode(key, v.Addr())
+	}
+	if v.Type().Implements(textMarshalerType) {
+		return textMarshalerNode(key, v)
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		return textMarshalerNode(key, v.Addr())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return BoolNode(key, v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int64Node(key, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NumberNode(key, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		value := v.Float()
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, errorRequest("unsupported value %v", value)
+		}
+		return NumberNode(key, formatFloat(value, v.Type().Bits()))
+	case reflect.String:
+		if v.Type() == numberType {
+			number := v.String()
+			if number == "" {
+				number = "0"
+			}
+			return NumberNode(key, number)
+		}
+		return StringNode(key, v.String()), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return NullNode(key), nil
+		}
+		return valueOf(key, v.Elem(), visited)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return NullNode(key), nil
+		}
+		if visited[v.Pointer()] {
+			return nil, errorRequest("encountered a cycle via %s", v.Type())
+		}
+		visited[v.Pointer()] = true
+		defer delete(visited, v.Pointer())
+		return valueOf(key, v.Elem(), visited)
+	case reflect.Slice:
+		if v.IsNil() {
+			return NullNode(key), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(v.Type().Elem()).Implements(marshalerType) &&
+			!reflect.PtrTo(v.Type().Elem()).Implements(textMarshalerType) {
+			return StringNode(key, base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		if v.Len() > 0 {
+			if visited[v.Pointer()] {
+				return nil, errorRequest("encountered a cycle via %s", v.Type())
+			}
+			visited[v.Pointer()] = true
+			defer delete(visited, v.Pointer())
+		}
+		return arrayOf(key, v, visited)
+	case reflect.Array:
+		return arrayOf(key, v, visited)
+	case reflect.Map:
+		if v.IsNil() {
+			return NullNode(key), nil
+		}
+		if visited[v.Pointer()] {
+			return nil, errorRequest("encountered a cycle via %s", v.Type())
+		}
+		visited[v.Pointer()] = true
+		defer delete(visited, v.Pointer())
+		return mapOf(key, v, visited)
+	case reflect.Struct:
+		return structOf(key, v, visited)
+	}
+	return nil, errorRequest("unsupported type %s", v.Type())
+}
+
+func arrayOf(key string, v reflect.Value, visited map[uintptr]bool) (*Node, error) {
+	elements := make([]*Node, v.Len())
+	for i := range elements {
+		element, err := valueOf("", v.Index(i), visited)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = element
+	}
+	node := ArrayNode(key, elements)
+	for _, element := range elements {
+		element.key = nil
+	}
+	return node, nil
+}
+
+func mapOf(key string, v reflect.Value, visited map[uintptr]bool) (*Node, error) {
+	kt := v.Type().Key()
+	if kt.Kind() != reflect.String && !kt.Implements(textMarshalerType) {
+		switch kt.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return nil, errorRequest("unsupported map key type %s", kt)
+		}
+	}
+	// keys are sorted by ObjectNode, same as encoding/json does
+	children := make(map[string]*Node, v.Len())
+	for _, kv := range v.MapKeys() {
+		var name string
+		switch {
+		case kv.Kind() == reflect.String:
+			name = kv.String()
+		case kt.Implements(textMarshalerType):
+			if kv.Kind() == reflect.Ptr && kv.IsNil() {
+				break
+			}
+			text, err := kv.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			name = string(text)
+		case kv.Kind() >= reflect.Int && kv.Kind() <= reflect.Int64:
+			name = strconv.FormatInt(kv.Int(), 10)
+		default:
+			name = strconv.FormatUint(kv.Uint(), 10)
+		}
+		child, err := valueOf(name, v.MapIndex(kv), visited)
+		if err != nil {
+			return nil, err
+		}
+		children[name] = child
+	}
+	return ObjectNode(key, children), nil
+}
+
+func structOf(key string, v reflect.Value, visited map[uintptr]bool) (*Node, error) {
+	node := ObjectNode(key, nil)
+	for _, f := range cachedFields(v.Type()).list {
+		value, ok := embeddedField(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(value)) {
+			continue
+		}
+		child, err := valueOf(f.name, value, visited)
+		if err != nil {
+			return nil, err
+		}
+		if f.quoted && !child.IsNull() {
+			data, err := Marshal(child)
+			if err != nil {
+				return nil, err
+			}
+			child = StringNode(f.name, string(data))
+		}
+		if err = node.AppendObject(f.name, child); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// embeddedField returns the field of the struct, or false if one of embedded pointers is nil
+func embeddedField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func marshalerNode(key string, v reflect.Value) (*Node, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return NullNode(key), nil
+	}
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	node.key = &key
+	return node, nil
+}
+
+func textMarshalerNode(key string, v reflect.Value) (*Node, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return NullNode(key), nil
+	}
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return StringNode(key, string(text)), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package ajson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encodeKey int
+
+func (k encodeKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("key-%d", int(k))), nil
+}
+
+type encodeMarshaler struct {
+	Value string
+}
+
+func (m encodeMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"wrapped":"` + m.Value + `"}`), nil
+}
+
+type encodeFailure struct{}
+
+func (encodeFailure) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("failure")
+}
+
+func TestValueOf(t *testing.T) {
+	type Base struct {
+		ID      int64  `json:"id"`
+		Created string `json:"created,omitempty"`
+	}
+	type Meta struct {
+		Tags []string `json:"tags"`
+	}
+	type Item struct {
+		Base
+		*Meta
+		Name     string             `json:"name"`
+		Price    float64            `json:"price,string"`
+		Count    *int               `json:"count"`
+		Enabled  bool               `json:"enabled,string"`
+		Title    string             `json:"title,string"`
+		Skipped  string             `json:"-"`
+		Empty    []int              `json:"empty,omitempty"`
+		Extra    map[string]int     `json:"extra"`
+		Sizes    [2]int             `json:"sizes"`
+		Any      interface{}        `json:"any"`
+		Number   json.Number        `json:"number"`
+		Time     time.Time          `json:"time"`
+		IP       net.IP             `json:"ip"`
+		Data     []byte             `json:"data"`
+		Children []Item             `json:"children"`
+		Labels   map[int]string     `json:"labels"`
+		Keys     map[encodeKey]bool `json:"keys"`
+		Wrapped  encodeMarshaler    `json:"wrapped"`
+		Small    float32            `json:"small"`
+		Html     string             `json:"html"`
+		Default  string
+		private  string
+	}
+	count := 3
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "nil", value: nil},
+		{name: "bool", value: true},
+		{name: "int", value: -42},
+		{name: "int64", value: int64(math.MaxInt64)},
+		{name: "uint64", value: uint64(math.MaxUint64)},
+		{name: "float", value: 1.5},
+		{name: "float small", value: 1e-7},
+		{name: "float large", value: 1e21},
+		{name: "float32", value: float32(1.1)},
+		{name: "string", value: "text\n<&> "},
+		{name: "slice", value: []interface{}{1, "2", nil, true, []int{}}},
+		{name: "nil slice", value: []int(nil)},
+		{name: "bytes", value: []byte("hello")},
+		{name: "map", value: map[string]interface{}{"b": 1, "a": []string{"x"}, "c": map[string]int{}}},
+		{name: "nil pointer", value: (*int)(nil)},
+		{name: "time", value: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "struct", value: Item{
+			Base:     Base{ID: 9007199254740993},
+			Meta:     &Meta{Tags: []string{"a"}},
+			Name:     "item",
+			Price:    1.25,
+			Count:    &count,
+			Enabled:  true,
+			Title:    "quoted",
+			Skipped:  "skipped",
+			Extra:    map[string]int{"y": 2, "x": 1},
+			Sizes:    [2]int{5, 6},
+			Any:      map[string]interface{}{"k": []interface{}{1.5, nil}},
+			Number:   "1.50",
+			IP:       net.IPv4(127, 0, 0, 1),
+			Data:     []byte{0, 1, 2},
+			Children: []Item{{Name: "child"}},
+			Labels:   map[int]string{10: "ten", -2: "minus two"},
+			Keys:     map[encodeKey]bool{1: true, 2: false},
+			Wrapped:  encodeMarshaler{Value: "value"},
+			Small:    0.1,
+			Html:     "<b>",
+			Default:  "default",
+			private:  "private",
+		}},
+		{name: "struct pointer", value: &Item{Name: "empty"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := json.Marshal(test.value)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			node, err := ValueOf(test.value)
+			if err != nil {
+				t.Fatalf("ValueOf() error = %v", err)
+			}
+			if node.Path() != "$" {
+				t.Errorf("ValueOf() wrong path of the root: %s", node.Path())
+			}
+			if result := marshalString(node); result != string(expected) {
+				t.Errorf("ValueOf() = %s\nexpected   %s", result, expected)
+			}
+		})
+	}
+}
+
+func TestValueOf_node(t *testing.T) {
+	raw := Must(Unmarshal([]byte(`{"a": [1.50]}`)))
+	value := struct {
+		Raw  *Node `json:"raw"`
+		Null *Node `json:"null"`
+	}{Raw: raw.MustKey("a")}
+	root, err := ValueOf(value)
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	if result := marshalString(root); result != `{"raw":[1.50],"null":null}` {
+		t.Errorf("ValueOf() = %s", result)
+	}
+	if err = root.MustKey("raw").AppendArray(NullNode("")); err != nil {
+		t.Fatalf("AppendArray() error = %v", err)
+	}
+	if result := marshalString(raw); result != `{"a": [1.50]}` {
+		t.Errorf("ValueOf() changed the original node: %s", result)
+	}
+}
+
+func TestValueOf_mutate(t *testing.T) {
+	root, err := ValueOf(map[string]interface{}{
+		"store": map[string]interface{}{
+			"book": []map[string]interface{}{
+				{"title": "A", "price": 8.95},
+				{"title": "B", "price": 22.99},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	nodes, err := root.JSONPath("$.store.book[?(@.price < 10)].title")
+	if err != nil {
+		t.Fatalf("JSONPath() error = %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].MustString() != "A" || nodes[0].Path() != "$['store']['book'][0]['title']" {
+		t.Fatalf("JSONPath() wrong result: %v", nodes)
+	}
+	if err = nodes[0].SetString("C"); err != nil {
+		t.Fatalf("SetString() error = %v", err)
+	}
+	if err = root.MustKey("store").MustKey("book").AppendArray(ObjectNode("", nil)); err != nil {
+		t.Fatalf("AppendArray() error = %v", err)
+	}
+	expected := `{"store":{"book":[{"price":8.95,"title":"C"},{"price":22.99,"title":"B"},{}]}}`
+	if result := marshalString(root); result != expected {
+		t.Errorf("Marshal() = %s, expected %s", result, expected)
+	}
+}
+
+func TestValueOf_errors(t *testing.T) {
+	type Cycle struct {
+		Next *Cycle
+	}
+	cycle := &Cycle{}
+	cycle.Next = cycle
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "NaN", value: math.NaN()},
+		{name: "Inf", value: math.Inf(-1)},
+		{name: "channel", value: make(chan int)},
+		{name: "function", value: func() {}},
+		{name: "complex", value: complex(1, 2)},
+		{name: "map key", value: map[float64]int{1: 1}},
+		{name: "nested", value: []interface{}{1, map[string]interface{}{"a": math.NaN()}}},
+		{name: "marshaler", value: encodeFailure{}},
+		{name: "number", value: json.Number("1.")},
+		{name: "pointer cycle", value: cycle},
+		{name: "map cycle", value: cyclic},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ValueOf(test.value); err == nil {
+				t.Errorf("ValueOf() expected error")
+			}
+		})
+	}
+	shared := []int{1}
+	if _, err := ValueOf([][]int{shared, shared}); err != nil {
+		t.Errorf("ValueOf() error = %v", err)
+	}
+}
+
+func TestValueOf_decode(t *testing.T) {
+	type Value struct {
+		ID    uint64            `json:"id,string"`
+		Names []string          `json:"names"`
+		Map   map[string]string `json:"map,omitempty"`
+		Time  time.Time         `json:"time"`
+	}
+	value := Value{ID: math.MaxUint64, Names: []string{"a", "b"}, Time: time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)}
+	node, err := ValueOf(value)
+	if err != nil {
+		t.Fatalf("ValueOf() error = %v", err)
+	}
+	var result Value
+	if err = node.Decode(&result); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if result.ID != value.ID || strings.Join(result.Names, ",") != "a,b" || result.Map != nil || !result.Time.Equal(value.Time) {
+		t.Errorf("Decode() = %+v, expected %+v", result, value)
+	}
+}
+
+func ExampleValueOf() {
+	type Book struct {
+		Title string   `json:"title"`
+		Price float64  `json:"price"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	root, err := ValueOf(map[string]interface{}{
+		"books": []Book{{Title: "Moby Dick", Price: 8.99}, {Title: "Sword of Honour", Price: 12.99, Tags: []string{"war"}}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	nodes, _ := root.JSONPath("$..books[?(@.price > 10)]")
+	for _, node := range nodes {
+		_ = node.AppendObject("expensive", BoolNode("", true))
+	}
+	result, _ := Marshal(root)
+	fmt.Printf("%s\n", result)
+	// Output:
+	// {"books":[{"title":"Moby Dick","price":8.99},{"title":"Sword of Honour","price":12.99,"tags":["war"],"expensive":true}]}
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spyzhov/ajson"
+)
+
+// formats are the checks of the `format` keyword, unknown formats are ignored
+var formats = map[string]func(value string) bool{
+	"date-time":     isDateTime,
+	"date":          isDate,
+	"time":          isTime,
+	"duration":      isDuration,
+	"email":         isEmail,
+	"hostname":      isHostname,
+	"ipv4":          isIPv4,
+	"ipv6":          isIPv6,
+	"uri":           isURI,
+	"uri-reference": isURIReference,
+	"uuid":          isUUID,
+	"regex":         isRegex,
+	"json-pointer":  isJSONPointer,
+}
+
+var (
+	durationPattern = regexp.MustCompile(`^P(?:\d+W|(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+S)?)?)$`)
+	hostnamePattern = regexp.MustCompile(`^(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)(?:\.(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?))*$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// isDateTime checks the `date-time` of RFC 3339, e.g.: `2020-01-02T03:04:05.678Z`
+func isDateTime(value string) bool {
+	value = strings.ToUpper(value)
+	index := strings.IndexByte(value, 'T')
+	return index != -1 && isDate(value[:index]) && isTime(value[index+1:])
+}
+
+// isDate checks the `full-date` of RFC 3339, e.g.: `2020-01-02`
+func isDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+// isTime checks the `full-time` of RFC 3339, e.g.: `03:04:05+01:00`. Leap second is allowed.
+func isTime(value string) bool {
+	value = strings.ToUpper(value)
+	if len(value) < 9 || value[2] != ':' || value[5] != ':' {
+		return false // the time package allows the one-digit hour
+	}
+	if value[6:8] == "60" {
+		// leap second can't be parsed by the time package
+		value = value[:6] + "59" + value[8:]
+	}
+	_, err := time.Parse(time.RFC3339, "2000-01-01T"+value)
+	return err == nil
+}
+
+// isDuration checks the `duration` of RFC 3339 (appendix A), e.g.: `P1DT2H`
+func isDuration(value string) bool {
+	return value != "P" && !strings.HasSuffix(value, "T") && durationPattern.MatchString(value)
+}
+
+// isEmail checks the email address of RFC 5321, e.g.: `user@example.com`
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value && address.Name == ""
+}
+
+// isHostname checks the hostname of RFC 1123, e.g.: `www.example.com`
+func isHostname(value string) bool {
+	return len(strings.TrimSuffix(value, ".")) <= 253 && hostnamePattern.MatchString(value)
+}
+
+// isIPv4 checks the IPv4 address in the dotted-quad notation, e.g.: `127.0.0.1`
+func isIPv4(value string) bool {
+	for _, part := range strings.Split(value, ".") {
+		if len(part) > 1 && part[0] == '0' {
+			return false // leading zeros are ambiguous
+		}
+	}
+	return strings.Count(value, ".") == 3 && net.ParseIP(value) != nil
+}
+
+// isIPv6 checks the IPv6 address of RFC 4291, e.g.: `::1`
+func isIPv6(value string) bool {
+	return strings.Contains(value, ":") && !strings.Contains(value, "%") && net.ParseIP(value) != nil
+}
+
+// isURI checks the absolute URI of RFC 3986, e.g.: `https://example.com/path`
+func isURI(value string) bool {
+	result, err := url.Parse(value)
+	return err == nil && result.IsAbs() && !strings.ContainsAny(value, " \\")
+}
+
+// isURIReference checks the URI or the relative reference of RFC 3986, e.g.: `../path#fragment`
+func isURIReference(value string) bool {
+	_, err := url.Parse(value)
+	return err == nil && !strings.ContainsAny(value, " \\")
+}
+
+// isUUID checks the UUID of RFC 4122, e.g.: `123e4567-e89b-12d3-a456-426614174000`
+func isUUID(value string) bool {
+	return uuidPattern.MatchString(value)
+}
+
+// isRegex checks the regular expression, by the same RE2 syntax as the `pattern` keyword
+func isRegex(value string) bool {
+	_, err := regexp.Compile(value)
+	return err == nil
+}
+
+// isJSONPointer checks the JSON Pointer of RFC 6901, e.g.: `/store/book/0`
+func isJSONPointer(value string) bool {
+	if _, err := ajson.ParseJSONPointer(value); err != nil {
+		return false
+	}
+	// `~` should be escaped as `~0` or `~1`
+	for i := 0; i < len(value); i++ {
+		if value[i] == '~' && (i+1 == len(value) || (value[i+1] != '0' && value[i+1] != '1')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{
+			format:  "date-time",
+			valid:   []string{"2020-01-02T03:04:05Z", "2020-01-02t03:04:05.678+01:00", "1998-12-31T23:59:60Z"},
+			invalid: []string{"2020-01-02", "2020-01-02T03:04:05", "2020-13-02T03:04:05Z", "2020-01-02 03:04:05Z"},
+		},
+		{
+			format:  "date",
+			valid:   []string{"2020-02-29"},
+			invalid: []string{"2021-02-29", "2020-1-2", "20200102"},
+		},
+		{
+			format:  "time",
+			valid:   []string{"03:04:05Z", "23:59:59.999-08:00"},
+			invalid: []string{"03:04:05", "24:00:00Z", "3:04:05Z"},
+		},
+		{
+			format:  "duration",
+			valid:   []string{"P1D", "PT1H30M", "P1Y2M3DT4H5M6S", "P2W"},
+			invalid: []string{"P", "PT", "1D", "P1DT", "P1W2D"},
+		},
+		{
+			format:  "email",
+			valid:   []string{"user@example.com", "first.last+tag@sub.example.org"},
+			invalid: []string{"nobody", "@example.com", "User <user@example.com>"},
+		},
+		{
+			format:  "hostname",
+			valid:   []string{"example.com", "a-b.example", "localhost"},
+			invalid: []string{"-a.com", "a..b", "a_b.com", ""},
+		},
+		{
+			format:  "ipv4",
+			valid:   []string{"127.0.0.1", "255.255.255.255"},
+			invalid: []string{"256.0.0.1", "1.2.3", "01.2.3.4", "::1"},
+		},
+		{
+			format:  "ipv6",
+			valid:   []string{"::1", "2001:db8::8a2e:370:7334", "::ffff:127.0.0.1"},
+			invalid: []string{"127.0.0.1", "12345::", "fe80::1%eth0"},
+		},
+		{
+			format:  "uri",
+			valid:   []string{"https://example.com/a?b=c#d", "urn:isbn:0451450523"},
+			invalid: []string{"/relative/path", "https://exa mple.com", "//example.com"},
+		},
+		{
+			format:  "uri-reference",
+			valid:   []string{"../path#fragment", "https://example.com", ""},
+			invalid: []string{"a b", "\\\\server"},
+		},
+		{
+			format:  "uuid",
+			valid:   []string{"123e4567-e89b-12d3-a456-426614174000"},
+			invalid: []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"},
+		},
+		{
+			format:  "regex",
+			valid:   []string{"^a+$", "[0-9]{2,}"},
+			invalid: []string{"(a", "[z-a]"},
+		},
+		{
+			format:  "json-pointer",
+			valid:   []string{"", "/a/b~0c/d~1e/0", "/"},
+			invalid: []string{"a/b", "/a~2", "/a~"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			check := formats[test.format]
+			for _, value := range test.valid {
+				if !check(value) {
+					t.Errorf("%s: '%s' should be valid", test.format, value)
+				}
+			}
+			for _, value := range test.invalid {
+				if check(value) {
+					t.Errorf("%s: '%s' should be invalid", test.format, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spyzhov/ajson"
+)
+
+// maxEnum is the maximum count of distinct strings, which are inferred as enum
+const maxEnum = 10
+
+// typeOrder is the order of types in the inferred schema
+var typeOrder = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// shape is the summary of all nodes, found at the same place of the samples
+type shape struct {
+	types map[string]int
+
+	strings  map[string]bool
+	count    int
+	overflow bool
+
+	minimum *ajson.Node
+	maximum *ajson.Node
+
+	items *shape
+
+	objects    int
+	properties map[string]*shape
+	keys       []string
+}
+
+// InferSchema returns the JSON Schema (draft 2020-12), which describes all given samples:
+//
+//   - types are united, e.g.: `["string", "null"]`;
+//   - keys present in all objects are required, others are optional;
+//   - strings with at most 10 distinct and repeated values become enum;
+//   - numbers get the observed range as minimum and maximum.
+//
+// Example:
+//
+//	inferred, err := schema.InferSchema(first, second)
+//	// {"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{...},"required":[...]}
+func InferSchema(samples ...*ajson.Node) (*ajson.Node, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("samples are not set")
+	}
+	root := newShape()
+	for i, sample := range samples {
+		if sample == nil {
+			return nil, fmt.Errorf("sample %d is nil", i)
+		}
+		if err := root.add(sample); err != nil {
+			return nil, err
+		}
+	}
+	result := ajson.ObjectNode("", nil)
+	if err := result.AppendObject("$schema", ajson.StringNode("", "https://json-schema.org/draft/2020-12/schema")); err != nil {
+		return nil, err
+	}
+	if err := root.build(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func newShape() *shape {
+	return &shape{
+		types:   make(map[string]int),
+		strings: make(map[string]bool),
+	}
+}
+
+// add adds the node into the summary
+func (s *shape) add(node *ajson.Node) error {
+	name := typeOf(node)
+	s.types[name]++
+	switch name {
+	case "string":
+		value, err := node.GetString()
+		if err != nil {
+			return err
+		}
+		s.count++
+		if !s.overflow && !s.strings[value] {
+			if len(s.strings) == maxEnum {
+				s.overflow = true
+				s.strings = nil
+			} else {
+				s.strings[value] = true
+			}
+		}
+	case "integer", "number":
+		if s.minimum == nil || compare(node, s.minimum) < 0 {
+			s.minimum = node
+		}
+		if s.maximum == nil || compare(node, s.maximum) > 0 {
+			s.maximum = node
+		}
+	case "array":
+		for _, element := range node.Inheritors() {
+			if s.items == nil {
+				s.items = newShape()
+			}
+			if err := s.items.add(element); err != nil {
+				return err
+			}
+		}
+	case "object":
+		s.objects++
+		if s.properties == nil {
+			s.properties = make(map[string]*shape)
+		}
+		for _, key := range node.Keys() {
+			property, ok := s.properties[key]
+			if !ok {
+				property = newShape()
+				s.properties[key] = property
+				s.keys = append(s.keys, key)
+			}
+			if err := property.add(node.MustKey(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// total returns the count of nodes in the summary
+func (s *shape) total() (result int) {
+	for _, count := range s.types {
+		result += count
+	}
+	return
+}
+
+// build appends the keywords of the inferred schema into the result
+func (s *shape) build(result *ajson.Node) error {
+	var types []string
+	for _, name := range typeOrder {
+		if s.types[name] > 0 && !(name == "integer" && s.types["number"] > 0) {
+			types = append(types, name)
+		}
+	}
+	var err error
+	if len(types) == 1 {
+		err = result.AppendObject("type", ajson.StringNode("", types[0]))
+	} else {
+		values := make([]*ajson.Node, 0, len(types))
+		for _, name := range types {
+			values = append(values, ajson.StringNode("", name))
+		}
+		err = result.AppendObject("type", ajson.ArrayNode("", values))
+	}
+	if err != nil {
+		return err
+	}
+
+	// strings are enum, if they are the only type and values are repeated
+	if len(types) == 1 && types[0] == "string" && !s.overflow && s.count > len(s.strings) {
+		values := make([]string, 0, len(s.strings))
+		for value := range s.strings {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		enum := make([]*ajson.Node, 0, len(values))
+		for _, value := range values {
+			enum = append(enum, ajson.StringNode("", value))
+		}
+		if err = result.AppendObject("enum", ajson.ArrayNode("", enum)); err != nil {
+			return err
+		}
+	}
+	if s.minimum != nil {
+		if err = appendNumber(result, "minimum", s.minimum); err != nil {
+			return err
+		}
+		if err = appendNumber(result, "maximum", s.maximum); err != nil {
+			return err
+		}
+	}
+	if s.items != nil {
+		items := ajson.ObjectNode("", nil)
+		if err = s.items.build(items); err != nil {
+			return err
+		}
+		if err = result.AppendObject("items", items); err != nil {
+			return err
+		}
+	}
+	if s.objects > 0 {
+		properties := ajson.ObjectNode("", nil)
+		required := make([]*ajson.Node, 0, len(s.keys))
+		for _, key := range s.keys {
+			property := ajson.ObjectNode("", nil)
+			if err = s.properties[key].build(property); err != nil {
+				return err
+			}
+			if err = properties.AppendObject(key, property); err != nil {
+				return err
+			}
+			if s.properties[key].total() == s.objects {
+				required = append(required, ajson.StringNode("", key))
+			}
+		}
+		if err = result.AppendObject("properties", properties); err != nil {
+			return err
+		}
+		if len(required) > 0 {
+			if err = result.AppendObject("required", ajson.ArrayNode("", required)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appendNumber appends the copy of the number, as it was written in the sample
+func appendNumber(result *ajson.Node, key string, value *ajson.Node) error {
+	node, err := ajson.NumberNode("", number(value))
+	if err != nil {
+		return err
+	}
+	return result.AppendObject(key, node)
+}
@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		expected string
+	}{
+		{
+			name:     "scalar",
+			samples:  []string{`"a"`},
+			expected: `{"type":"string"}`,
+		},
+		{
+			name:     "types",
+			samples:  []string{`1`, `null`, `true`},
+			expected: `{"type":["null","boolean","integer"],"minimum":1,"maximum":1}`,
+		},
+		{
+			name:     "numbers",
+			samples:  []string{`3`, `-1.50`, `1e2`},
+			expected: `{"type":"number","minimum":-1.50,"maximum":1e2}`,
+		},
+		{
+			name:     "enum",
+			samples:  []string{`"b"`, `"a"`, `"b"`},
+			expected: `{"type":"string","enum":["a","b"]}`,
+		},
+		{
+			name:     "unique strings",
+			samples:  []string{`"b"`, `"a"`, `"c"`},
+			expected: `{"type":"string"}`,
+		},
+		{
+			name:     "too many strings",
+			samples:  []string{`["a","b","c","d","e","f","g","h","i","j","k","a"]`},
+			expected: `{"type":"array","items":{"type":"string"}}`,
+		},
+		{
+			name:     "nullable enum",
+			samples:  []string{`"a"`, `"a"`, `null`},
+			expected: `{"type":["null","string"]}`,
+		},
+		{
+			name:     "arrays",
+			samples:  []string{`[]`, `[1, "a"]`, `[2.5]`},
+			expected: `{"type":"array","items":{"type":["number","string"],"minimum":1,"maximum":2.5}}`,
+		},
+		{
+			name:     "empty array",
+			samples:  []string{`[]`},
+			expected: `{"type":"array"}`,
+		},
+		{
+			name: "objects",
+			samples: []string{
+				`{"id": 1, "name": "a", "role": "admin", "tags": ["x"]}`,
+				`{"id": 2, "role": "user", "extra": null}`,
+				`{"id": 3, "name": "c", "role": "admin"}`,
+			},
+			expected: `{"type":"object","properties":{` +
+				`"id":{"type":"integer","minimum":1,"maximum":3},` +
+				`"name":{"type":"string"},` +
+				`"role":{"type":"string","enum":["admin","user"]},` +
+				`"tags":{"type":"array","items":{"type":"string"}},` +
+				`"extra":{"type":"null"}` +
+				`},"required":["id","role"]}`,
+		},
+		{
+			name:     "nested",
+			samples:  []string{`{"a": [{"b": 1}, {"b": 2, "c": true}]}`, `{"a": null}`},
+			expected: `{"type":"object","properties":{"a":{"type":["null","array"],"items":{"type":"object","properties":{"b":{"type":"integer","minimum":1,"maximum":2},"c":{"type":"boolean"}},"required":["b"]}}},"required":["a"]}`,
+		},
+		{
+			name:     "empty object",
+			samples:  []string{`{}`},
+			expected: `{"type":"object","properties":{}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples := make([]*ajson.Node, 0, len(test.samples))
+			for _, sample := range test.samples {
+				samples = append(samples, ajson.Must(ajson.Unmarshal([]byte(sample))))
+			}
+			inferred, err := InferSchema(samples...)
+			if err != nil {
+				t.Fatalf("InferSchema() error = %v", err)
+			}
+			if err = inferred.DeleteKey("$schema"); err != nil {
+				t.Fatalf("InferSchema() has no $schema: %v", err)
+			}
+			result, err := ajson.Marshal(inferred)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("InferSchema() = %s\nexpected        %s", result, test.expected)
+			}
+			compiled, err := Compile(inferred)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			for _, sample := range samples {
+				if violations := compiled.Validate(sample); len(violations) != 0 {
+					t.Errorf("Validate() = %v, sample should be valid", violations)
+				}
+			}
+		})
+	}
+}
+
+func TestInferSchema_errors(t *testing.T) {
+	if _, err := InferSchema(); err == nil {
+		t.Errorf("InferSchema() expected error")
+	}
+	if _, err := InferSchema(ajson.NullNode(""), nil); err == nil {
+		t.Errorf("InferSchema() expected error")
+	}
+}
+
+func ExampleInferSchema() {
+	first := ajson.Must(ajson.Unmarshal([]byte(`{"id": 1, "status": "active", "email": "a@example.com"}`)))
+	second := ajson.Must(ajson.Unmarshal([]byte(`{"id": 7, "status": "active", "email": null, "admin": true}`)))
+	inferred, err := InferSchema(first, second)
+	if err != nil {
+		panic(err)
+	}
+	result, _ := ajson.Marshal(inferred)
+	fmt.Printf("%s\n", result)
+	// Output:
+	// {"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"id":{"type":"integer","minimum":1,"maximum":7},"status":{"type":"string","enum":["active"]},"email":{"type":["null","string"]},"admin":{"type":"boolean"}},"required":["id","status","email"]}
+}
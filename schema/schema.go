@@ -0,0 +1,603 @@
+// Package schema implements validation of ajson.Node trees by JSON Schema (draft 2020-12 and draft-07).
+//
+// Schema is given as *ajson.Node, so both the schema and the payload are parsed once:
+//
+//	compiled, err := schema.Compile(ajson.Must(ajson.Unmarshal(schemaJSON)))
+//	if err != nil {
+//		return err
+//	}
+//	for _, violation := range compiled.Validate(root) {
+//		fmt.Println(violation) // $['items'][1]['price']: -1 is less than minimum 0 (#/$defs/item/properties/price/minimum)
+//	}
+//
+// Local references (`$ref` to `#`, `#/json/pointer` and `#anchor`) are resolved, remote ones are not supported.
+// References, which loop back to the same schema without applying to any child of the instance, are rejected.
+// Keyword `format` is always asserted for the known formats: date-time, date, time, duration, email, hostname, ipv4,
+// ipv6, uri, uri-reference, uuid, regex and json-pointer.
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spyzhov/ajson"
+)
+
+// Draft is the version of the JSON Schema specification
+type Draft int
+
+const (
+	// Draft2020 is the JSON Schema draft 2020-12, used by default
+	Draft2020 Draft = iota
+	// Draft7 is the JSON Schema draft-07
+	Draft7
+)
+
+// String returns the name of the draft
+func (d Draft) String() string {
+	if d == Draft7 {
+		return "draft-07"
+	}
+	return "draft 2020-12"
+}
+
+// Schema is the compiled JSON Schema
+type Schema struct {
+	draft Draft
+	root  *schema
+}
+
+// Violation is the mismatch of the instance node with the schema
+type Violation struct {
+	// Path is the path of the instance node, e.g.: `$['items'][0]`
+	Path string
+	// Keyword is the location of the failed keyword in the schema, e.g.: `#/properties/items/items/type`
+	Keyword string
+	// Message is the description of the violation
+	Message string
+}
+
+// String returns the violation in the form: `path: message (keyword)`
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Path, v.Message, v.Keyword)
+}
+
+// schema is the compiled (sub)schema
+type schema struct {
+	location string
+	boolean  *bool
+	ref      *schema
+
+	types            []string
+	enum             []*ajson.Node
+	constant         *ajson.Node
+	multipleOf       *ajson.Node
+	maximum          *ajson.Node
+	exclusiveMaximum *ajson.Node
+	minimum          *ajson.Node
+	exclusiveMinimum *ajson.Node
+
+	maxLength *int
+	minLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	prefixItems     []*schema
+	items           *schema
+	additionalItems *schema
+	contains        *schema
+	maxContains     *int
+	minContains     *int
+	maxItems        *int
+	minItems        *int
+	uniqueItems     bool
+
+	properties           map[string]*schema
+	patternProperties    []patternProperty
+	additionalProperties *schema
+	propertyNames        *schema
+	required             []string
+	dependentRequired    map[string][]string
+	dependencies         map[string][]string
+	dependentSchemas     map[string]*schema
+	maxProperties        *int
+	minProperties        *int
+
+	allOf      []*schema
+	anyOf      []*schema
+	oneOf      []*schema
+	not        *schema
+	ifSchema   *schema
+	thenSchema *schema
+	elseSchema *schema
+}
+
+// patternProperty is the schema of properties, which names match the pattern
+type patternProperty struct {
+	pattern *regexp.Regexp
+	schema  *schema
+}
+
+// compiler keeps the state of the schema compilation
+type compiler struct {
+	draft    Draft
+	document *ajson.Node
+	id       string
+	compiled map[*ajson.Node]*schema
+	anchors  map[string]*ajson.Node
+}
+
+// Compile compiles the JSON Schema. The draft is detected by the `$schema` keyword: draft-07 for
+// `http://json-schema.org/draft-07/schema#`, draft 2020-12 otherwise.
+func Compile(document *ajson.Node) (*Schema, error) {
+	draft := Draft2020
+	if document != nil && document.IsObject() && document.HasKey("$schema") {
+		if value, err := document.MustKey("$schema").GetString(); err == nil && strings.Contains(value, "draft-07") {
+			draft = Draft7
+		}
+	}
+	return CompileDraft(document, draft)
+}
+
+// CompileDraft compiles the JSON Schema by the given draft, `$schema` keyword is ignored.
+func CompileDraft(document *ajson.Node, draft Draft) (*Schema, error) {
+	if document == nil {
+		return nil, fmt.Errorf("schema is not set")
+	}
+	c := &compiler{
+		draft:    draft,
+		document: document,
+		compiled: make(map[*ajson.Node]*schema),
+		anchors:  make(map[string]*ajson.Node),
+	}
+	if document.IsObject() && document.HasKey("$id") {
+		if id, err := document.MustKey("$id").GetString(); err == nil {
+			c.id = strings.TrimSuffix(id, "#")
+		}
+	}
+	c.collectAnchors(document)
+	root, err := c.compile(document)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.loops(); err != nil {
+		return nil, err
+	}
+	return &Schema{draft: draft, root: root}, nil
+}
+
+// MustCompile compiles the JSON Schema, panics if error happened
+func MustCompile(document *ajson.Node) *Schema {
+	result, err := Compile(document)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Draft returns the draft of the schema
+func (s *Schema) Draft() Draft {
+	return s.draft
+}
+
+// Validate checks the node by the schema and returns all found violations. Valid node has no violations.
+func (s *Schema) Validate(node *ajson.Node) []Violation {
+	var result []Violation
+	s.root.validate(node, &result)
+	return result
+}
+
+// Valid returns true if the node is valid by the schema
+func (s *Schema) Valid(node *ajson.Node) bool {
+	return len(s.Validate(node)) == 0
+}
+
+// location returns the location of the schema node, relative to the document
+func (c *compiler) location(node *ajson.Node) string {
+	return "#" + strings.TrimPrefix(node.Pointer(), c.document.Pointer())
+}
+
+// collectAnchors finds all `$anchor` keywords, and `$id` in the form of `#name` for draft-07
+func (c *compiler) collectAnchors(node *ajson.Node) {
+	switch node.Type() {
+	case ajson.Object:
+		for _, key := range node.Keys() {
+			child := node.MustKey(key)
+			switch key {
+			case "enum", "const", "default", "examples":
+				continue
+			case "$anchor":
+				if name, err := child.GetString(); err == nil {
+					c.anchors[name] = node
+				}
+			case "$id":
+				if name, err := child.GetString(); err == nil && strings.HasPrefix(name, "#") {
+					c.anchors[name[1:]] = node
+				}
+			}
+			c.collectAnchors(child)
+		}
+	case ajson.Array:
+		for _, child := range node.Inheritors() {
+			c.collectAnchors(child)
+		}
+	}
+}
+
+// resolve returns the schema node by the reference
+func (c *compiler) resolve(ref string) (*ajson.Node, error) {
+	if c.id != "" && strings.HasPrefix(ref, c.id) {
+		ref = ref[len(c.id):]
+	}
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("remote $ref is not supported: '%s'", ref)
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("wrong $ref '%s': %s", ref, err)
+	}
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		node, err := c.document.GetPointer(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("can't resolve $ref '%s': %s", ref, err)
+		}
+		return node, nil
+	}
+	if node, ok := c.anchors[fragment]; ok {
+		return node, nil
+	}
+	return nil, fmt.Errorf("can't resolve $ref '%s'", ref)
+}
+
+// compile compiles the (sub)schema, each node is compiled only once, so recursive references are supported
+func (c *compiler) compile(node *ajson.Node) (*schema, error) {
+	if result, ok := c.compiled[node]; ok {
+		return result, nil
+	}
+	result := &schema{location: c.location(node)}
+	c.compiled[node] = result
+
+	switch node.Type() {
+	case ajson.Bool:
+		value := node.MustBool()
+		result.boolean = &value
+		return result, nil
+	case ajson.Object:
+	default:
+		return nil, c.errorf(node, "schema should be an object or a boolean")
+	}
+
+	if node.HasKey("$ref") {
+		ref, err := node.MustKey("$ref").GetString()
+		if err != nil {
+			return nil, c.errorf(node.MustKey("$ref"), "should be a string")
+		}
+		target, err := c.resolve(ref)
+		if err != nil {
+			return nil, c.errorf(node.MustKey("$ref"), "%s", err)
+		}
+		if result.ref, err = c.compile(target); err != nil {
+			return nil, err
+		}
+		if c.draft == Draft7 {
+			// all other properties in a "$ref" object are ignored
+			return result, nil
+		}
+	}
+
+	for _, key := range node.Keys() {
+		if err := c.keyword(result, key, node.MustKey(key)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// loops checks that no schema applies itself to the same instance node through `$ref` and in-place applicators,
+// e.g.: `{"$ref": "#"}`, otherwise the validation would never end
+func (c *compiler) loops() error {
+	compiled := make([]*schema, 0, len(c.compiled))
+	for _, s := range c.compiled {
+		compiled = append(compiled, s)
+	}
+	sort.Slice(compiled, func(i, j int) bool {
+		return compiled[i].location < compiled[j].location
+	})
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*schema]int, len(compiled))
+	var visit func(s *schema) *schema
+	visit = func(s *schema) *schema {
+		switch state[s] {
+		case visiting:
+			return s
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+		for _, next := range s.inPlace() {
+			if found := visit(next); found != nil {
+				return found
+			}
+		}
+		state[s] = visited
+		return nil
+	}
+	for _, s := range compiled {
+		if found := visit(s); found != nil {
+			return fmt.Errorf("wrong schema at %s: infinite loop of $ref", found.location)
+		}
+	}
+	return nil
+}
+
+// inPlace returns the subschemas, which are applied to the same instance node
+func (s *schema) inPlace() []*schema {
+	var result []*schema
+	if s.ref != nil {
+		result = append(result, s.ref)
+	}
+	result = append(result, s.allOf...)
+	result = append(result, s.anyOf...)
+	result = append(result, s.oneOf...)
+	for _, next := range []*schema{s.not, s.ifSchema, s.thenSchema, s.elseSchema} {
+		if next != nil {
+			result = append(result, next)
+		}
+	}
+	for _, next := range s.dependentSchemas {
+		result = append(result, next)
+	}
+	return result
+}
+
+// keyword compiles the keyword of the schema, unknown keywords are ignored
+func (c *compiler) keyword(s *schema, key string, value *ajson.Node) (err error) {
+	switch key {
+	case "type":
+		if value.IsString() {
+			s.types = []string{value.MustString()}
+		} else if s.types, err = c.strings(value); err != nil {
+			return err
+		}
+		for _, name := range s.types {
+			switch name {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return c.errorf(value, "unknown type '%s'", name)
+			}
+		}
+	case "enum":
+		if !value.IsArray() {
+			return c.errorf(value, "should be an array")
+		}
+		s.enum = value.Inheritors()
+	case "const":
+		s.constant = value
+	case "multipleOf":
+		if s.multipleOf, err = c.number(value); err == nil && value.MustNumeric() <= 0 {
+			return c.errorf(value, "should be greater than 0")
+		}
+	case "maximum":
+		s.maximum, err = c.number(value)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = c.number(value)
+	case "minimum":
+		s.minimum, err = c.number(value)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = c.number(value)
+	case "maxLength":
+		s.maxLength, err = c.integer(value)
+	case "minLength":
+		s.minLength, err = c.integer(value)
+	case "pattern":
+		s.pattern, err = c.regexp(value)
+	case "format":
+		if !value.IsString() {
+			return c.errorf(value, "should be a string")
+		}
+		s.format = value.MustString()
+	case "prefixItems":
+		s.prefixItems, err = c.schemas(value)
+	case "items":
+		if value.IsArray() {
+			// draft-07 form of the tuple validation
+			s.prefixItems, err = c.schemas(value)
+		} else {
+			s.items, err = c.compile(value)
+		}
+	case "additionalItems":
+		s.additionalItems, err = c.compile(value)
+	case "contains":
+		s.contains, err = c.compile(value)
+	case "maxContains":
+		s.maxContains, err = c.integer(value)
+	case "minContains":
+		s.minContains, err = c.integer(value)
+	case "maxItems":
+		s.maxItems, err = c.integer(value)
+	case "minItems":
+		s.minItems, err = c.integer(value)
+	case "uniqueItems":
+		if !value.IsBool() {
+			return c.errorf(value, "should be a boolean")
+		}
+		s.uniqueItems = value.MustBool()
+	case "properties":
+		s.properties, err = c.schemaMap(value)
+	case "patternProperties":
+		var schemas map[string]*schema
+		if schemas, err = c.schemaMap(value); err != nil {
+			return err
+		}
+		for _, name := range value.Keys() {
+			pattern, err := regexp.Compile(name)
+			if err != nil {
+				return c.errorf(value.MustKey(name), "wrong pattern '%s': %s", name, err)
+			}
+			s.patternProperties = append(s.patternProperties, patternProperty{pattern: pattern, schema: schemas[name]})
+		}
+	case "additionalProperties":
+		s.additionalProperties, err = c.compile(value)
+	case "propertyNames":
+		s.propertyNames, err = c.compile(value)
+	case "required":
+		s.required, err = c.strings(value)
+	case "dependentRequired":
+		if !value.IsObject() {
+			return c.errorf(value, "should be an object")
+		}
+		s.dependentRequired = make(map[string][]string, value.Size())
+		for _, name := range value.Keys() {
+			if s.dependentRequired[name], err = c.strings(value.MustKey(name)); err != nil {
+				return err
+			}
+		}
+	case "dependentSchemas":
+		s.dependentSchemas, err = c.schemaMap(value)
+	case "dependencies":
+		// draft-07 keyword, joins dependentRequired and dependentSchemas
+		if !value.IsObject() {
+			return c.errorf(value, "should be an object")
+		}
+		for _, name := range value.Keys() {
+			child := value.MustKey(name)
+			if child.IsArray() {
+				if s.dependencies == nil {
+					s.dependencies = make(map[string][]string)
+				}
+				if s.dependencies[name], err = c.strings(child); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.dependentSchemas == nil {
+				s.dependentSchemas = make(map[string]*schema)
+			}
+			if s.dependentSchemas[name], err = c.compile(child); err != nil {
+				return err
+			}
+		}
+	case "maxProperties":
+		s.maxProperties, err = c.integer(value)
+	case "minProperties":
+		s.minProperties, err = c.integer(value)
+	case "allOf":
+		s.allOf, err = c.schemas(value)
+	case "anyOf":
+		s.anyOf, err = c.schemas(value)
+	case "oneOf":
+		s.oneOf, err = c.schemas(value)
+	case "not":
+		s.not, err = c.compile(value)
+	case "if":
+		s.ifSchema, err = c.compile(value)
+	case "then":
+		s.thenSchema, err = c.compile(value)
+	case "else":
+		s.elseSchema, err = c.compile(value)
+	case "$defs", "definitions":
+		// definitions are compiled to check them, even if they aren't referenced
+		_, err = c.schemaMap(value)
+	}
+	return err
+}
+
+func (c *compiler) schemas(node *ajson.Node) ([]*schema, error) {
+	if !node.IsArray() || node.Size() == 0 {
+		return nil, c.errorf(node, "should be a non-empty array")
+	}
+	result := make([]*schema, 0, node.Size())
+	for _, child := range node.Inheritors() {
+		compiled, err := c.compile(child)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, compiled)
+	}
+	return result, nil
+}
+
+func (c *compiler) schemaMap(node *ajson.Node) (map[string]*schema, error) {
+	if !node.IsObject() {
+		return nil, c.errorf(node, "should be an object")
+	}
+	result := make(map[string]*schema, node.Size())
+	for _, key := range node.Keys() {
+		compiled, err := c.compile(node.MustKey(key))
+		if err != nil {
+			return nil, err
+		}
+		result[key] = compiled
+	}
+	return result, nil
+}
+
+func (c *compiler) strings(node *ajson.Node) ([]string, error) {
+	if !node.IsArray() {
+		return nil, c.errorf(node, "should be an array of strings")
+	}
+	result := make([]string, 0, node.Size())
+	for _, child := range node.Inheritors() {
+		value, err := child.GetString()
+		if err != nil {
+			return nil, c.errorf(node, "should be an array of strings")
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func (c *compiler) number(node *ajson.Node) (*ajson.Node, error) {
+	if !node.IsNumeric() {
+		return nil, c.errorf(node, "should be a number")
+	}
+	return node, nil
+}
+
+func (c *compiler) integer(node *ajson.Node) (*int, error) {
+	if !node.IsNumeric() {
+		return nil, c.errorf(node, "should be a non-negative integer")
+	}
+	value, err := node.GetInt64()
+	if err != nil || value < 0 || int64(int(value)) != value {
+		return nil, c.errorf(node, "should be a non-negative integer")
+	}
+	result := int(value)
+	return &result, nil
+}
+
+// regexp compiles the pattern, by the same RE2 syntax as the `=~` operation of JSONPath
+func (c *compiler) regexp(node *ajson.Node) (*regexp.Regexp, error) {
+	pattern, err := node.GetString()
+	if err != nil {
+		return nil, c.errorf(node, "should be a string")
+	}
+	result, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, c.errorf(node, "wrong pattern '%s': %s", pattern, err)
+	}
+	return result, nil
+}
+
+func (c *compiler) errorf(node *ajson.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("wrong schema at %s: %s", c.location(node), fmt.Sprintf(format, args...))
+}
+
+// rat returns the exact value of the number, if its exponent is not too large
+func rat(node *ajson.Node) (*big.Rat, bool) {
+	number, err := node.GetNumberString()
+	if err != nil {
+		return nil, false
+	}
+	if index := strings.IndexAny(number, "eE"); index != -1 && len(strings.TrimLeft(number[index+1:], "+-0")) > 3 {
+		return nil, false
+	}
+	return new(big.Rat).SetString(number)
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		draft  Draft
+	}{
+		{name: "empty", schema: `{}`, draft: Draft2020},
+		{name: "boolean", schema: `false`, draft: Draft2020},
+		{name: "2020-12", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, draft: Draft2020},
+		{name: "draft-07", schema: `{"$schema": "http://json-schema.org/draft-07/schema#"}`, draft: Draft7},
+		{name: "unknown keywords", schema: `{"title": "x", "x-custom": [1], "examples": [{"$ref": "#/nowhere"}]}`, draft: Draft2020},
+		{name: "recursive", schema: `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"items": {"$ref": "#/$defs/a"}}}, "$ref": "#/$defs/a"}`, draft: Draft2020},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compiled, err := Compile(ajson.Must(ajson.Unmarshal([]byte(test.schema))))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if compiled.Draft() != test.draft {
+				t.Errorf("Draft() = %s, expected %s", compiled.Draft(), test.draft)
+			}
+		})
+	}
+}
+
+func TestCompile_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		location string
+	}{
+		{name: "number", schema: `1`, location: "#"},
+		{name: "type", schema: `{"type": 1}`, location: "#/type"},
+		{name: "unknown type", schema: `{"type": ["string", "text"]}`, location: "#/type"},
+		{name: "enum", schema: `{"enum": 1}`, location: "#/enum"},
+		{name: "multipleOf", schema: `{"multipleOf": 0}`, location: "#/multipleOf"},
+		{name: "maximum", schema: `{"maximum": "1"}`, location: "#/maximum"},
+		{name: "maxLength", schema: `{"maxLength": -1}`, location: "#/maxLength"},
+		{name: "minItems", schema: `{"minItems": 1.5}`, location: "#/minItems"},
+		{name: "pattern", schema: `{"pattern": "(a"}`, location: "#/pattern"},
+		{name: "patternProperties", schema: `{"patternProperties": {"[": {}}}`, location: "#/patternProperties/["},
+		{name: "format", schema: `{"format": 1}`, location: "#/format"},
+		{name: "uniqueItems", schema: `{"uniqueItems": 1}`, location: "#/uniqueItems"},
+		{name: "properties", schema: `{"properties": []}`, location: "#/properties"},
+		{name: "property", schema: `{"properties": {"a": 1}}`, location: "#/properties/a"},
+		{name: "required", schema: `{"required": [1]}`, location: "#/required"},
+		{name: "allOf", schema: `{"allOf": []}`, location: "#/allOf"},
+		{name: "nested", schema: `{"anyOf": [{"items": {"not": "x"}}]}`, location: "#/anyOf/0/items/not"},
+		{name: "definition", schema: `{"$defs": {"a": {"type": "x"}}}`, location: "#/$defs/a/type"},
+		{name: "ref type", schema: `{"$ref": 1}`, location: "#/$ref"},
+		{name: "ref missing", schema: `{"$ref": "#/$defs/a"}`, location: "#/$ref"},
+		{name: "ref anchor", schema: `{"$ref": "#item"}`, location: "#/$ref"},
+		{name: "ref remote", schema: `{"$ref": "https://example.com/schema.json"}`, location: "#/$ref"},
+		{name: "ref wrong", schema: `{"$ref": "#/%zz"}`, location: "#/$ref"},
+		{name: "dependencies", schema: `{"dependencies": {"a": [1]}}`, location: "#/dependencies/a"},
+		{name: "ref self", schema: `{"$ref": "#"}`, location: "#"},
+		{name: "ref mutual", schema: `{"$ref": "#/$defs/a", "$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}}`, location: "#/$defs/a"},
+		{name: "ref allOf", schema: `{"allOf": [{"$ref": "#"}]}`, location: "#"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compile(ajson.Must(ajson.Unmarshal([]byte(test.schema))))
+			if err == nil {
+				t.Fatalf("Compile() expected error")
+			}
+			if !strings.Contains(err.Error(), "wrong schema at "+test.location+":") {
+				t.Errorf("Compile() error = %v, expected location %s", err, test.location)
+			}
+		})
+	}
+	if _, err := Compile(nil); err == nil {
+		t.Errorf("Compile() expected error")
+	}
+}
+
+func TestCompileDraft(t *testing.T) {
+	document := ajson.Must(ajson.Unmarshal([]byte(`{"definitions": {"a": {"type": "integer"}}, "$ref": "#/definitions/a", "minimum": 5}`)))
+	instance := ajson.Must(ajson.Unmarshal([]byte(`1`)))
+	if violations := MustCompile(document).Validate(instance); len(violations) != 1 {
+		t.Errorf("Validate() = %v, expected minimum violation", violations)
+	}
+	compiled, err := CompileDraft(document, Draft7)
+	if err != nil {
+		t.Fatalf("CompileDraft() error = %v", err)
+	}
+	if violations := compiled.Validate(instance); len(violations) != 0 {
+		t.Errorf("Validate() = %v, siblings of $ref should be ignored", violations)
+	}
+}
+
+func TestCompile_subtree(t *testing.T) {
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"components": {"schemas": {"Item": {"properties": {"id": {"$ref": "#/$defs/id"}}, "$defs": {"id": {"type": "integer"}}}}}}`)))
+	document, err := root.GetPointer("/components/schemas/Item")
+	if err != nil {
+		t.Fatalf("GetPointer() error = %v", err)
+	}
+	violations := MustCompile(document).Validate(ajson.Must(ajson.Unmarshal([]byte(`{"id": "1"}`))))
+	if len(violations) != 1 || violations[0].Keyword != "#/$defs/id/type" {
+		t.Errorf("Validate() = %v", violations)
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompile() expected panic")
+		}
+	}()
+	MustCompile(ajson.Must(ajson.Unmarshal([]byte(`{"type": 1}`))))
+}
+
+func ExampleSchema_Validate() {
+	compiled, err := Compile(ajson.Must(ajson.Unmarshal([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["id", "email"],
+		"properties": {
+			"id": {"type": "integer"},
+			"email": {"type": "string", "format": "email"},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+		},
+		"additionalProperties": false
+	}`))))
+	if err != nil {
+		panic(err)
+	}
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"id": 1.5, "email": "nobody", "tags": ["a", 1, "a"], "role": "admin"}`)))
+	for _, violation := range compiled.Validate(root) {
+		fmt.Println(violation)
+	}
+	// Output:
+	// $['id']: expected integer, got number (#/properties/id/type)
+	// $['email']: 'nobody' is not a valid email (#/properties/email/format)
+	// $['tags']: items 0 and 2 are equal (#/properties/tags/uniqueItems)
+	// $['tags'][1]: expected string, got integer (#/properties/tags/items/type)
+	// $: additional property 'role' is not allowed (#/additionalProperties)
+}
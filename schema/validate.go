@@ -0,0 +1,341 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spyzhov/ajson"
+)
+
+// validate checks the node and appends found violations into the result
+func (s *schema) validate(node *ajson.Node, result *[]Violation) {
+	if s.boolean != nil {
+		if !*s.boolean {
+			s.fail(node, result, "", "value is not allowed")
+		}
+		return
+	}
+	if s.ref != nil {
+		s.ref.validate(node, result)
+	}
+
+	s.validateGeneric(node, result)
+	switch node.Type() {
+	case ajson.Numeric:
+		s.validateNumber(node, result)
+	case ajson.String:
+		s.validateString(node, result)
+	case ajson.Array:
+		s.validateArray(node, result)
+	case ajson.Object:
+		s.validateObject(node, result)
+	}
+	s.validateApplicators(node, result)
+}
+
+func (s *schema) validateGeneric(node *ajson.Node, result *[]Violation) {
+	if s.types != nil {
+		actual := typeOf(node)
+		matched := false
+		for _, name := range s.types {
+			if name == actual || (name == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			s.fail(node, result, "type", "expected %s, got %s", strings.Join(s.types, " or "), actual)
+		}
+	}
+	if s.enum != nil {
+		matched := false
+		for _, value := range s.enum {
+			if equal(node, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			s.fail(node, result, "enum", "value is not one of the enum")
+		}
+	}
+	if s.constant != nil && !equal(node, s.constant) {
+		s.fail(node, result, "const", "value is not equal to the const")
+	}
+}
+
+func (s *schema) validateNumber(node *ajson.Node, result *[]Violation) {
+	if s.multipleOf != nil && !multipleOf(node, s.multipleOf) {
+		s.fail(node, result, "multipleOf", "%s is not a multiple of %s", number(node), number(s.multipleOf))
+	}
+	if s.maximum != nil && compare(node, s.maximum) > 0 {
+		s.fail(node, result, "maximum", "%s is greater than maximum %s", number(node), number(s.maximum))
+	}
+	if s.exclusiveMaximum != nil && compare(node, s.exclusiveMaximum) >= 0 {
+		s.fail(node, result, "exclusiveMaximum", "%s is greater than or equal to exclusive maximum %s", number(node), number(s.exclusiveMaximum))
+	}
+	if s.minimum != nil && compare(node, s.minimum) < 0 {
+		s.fail(node, result, "minimum", "%s is less than minimum %s", number(node), number(s.minimum))
+	}
+	if s.exclusiveMinimum != nil && compare(node, s.exclusiveMinimum) <= 0 {
+		s.fail(node, result, "exclusiveMinimum", "%s is less than or equal to exclusive minimum %s", number(node), number(s.exclusiveMinimum))
+	}
+}
+
+func (s *schema) validateString(node *ajson.Node, result *[]Violation) {
+	value, err := node.GetString()
+	if err != nil {
+		return
+	}
+	length := utf8.RuneCountInString(value)
+	if s.maxLength != nil && length > *s.maxLength {
+		s.fail(node, result, "maxLength", "length %d is greater than %d", length, *s.maxLength)
+	}
+	if s.minLength != nil && length < *s.minLength {
+		s.fail(node, result, "minLength", "length %d is less than %d", length, *s.minLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		s.fail(node, result, "pattern", "'%s' does not match the pattern '%s'", value, s.pattern)
+	}
+	if s.format != "" {
+		if check, ok := formats[s.format]; ok && !check(value) {
+			s.fail(node, result, "format", "'%s' is not a valid %s", value, s.format)
+		}
+	}
+}
+
+func (s *schema) validateArray(node *ajson.Node, result *[]Violation) {
+	elements := node.Inheritors()
+	if s.maxItems != nil && len(elements) > *s.maxItems {
+		s.fail(node, result, "maxItems", "array has %d items, expected at most %d", len(elements), *s.maxItems)
+	}
+	if s.minItems != nil && len(elements) < *s.minItems {
+		s.fail(node, result, "minItems", "array has %d items, expected at least %d", len(elements), *s.minItems)
+	}
+	if s.uniqueItems {
+	unique:
+		for i := range elements {
+			for j := 0; j < i; j++ {
+				if equal(elements[i], elements[j]) {
+					s.fail(node, result, "uniqueItems", "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+	for i, element := range elements {
+		if i < len(s.prefixItems) {
+			s.prefixItems[i].validate(element, result)
+		} else if s.items != nil {
+			s.items.validate(element, result)
+		} else if s.additionalItems != nil && s.prefixItems != nil {
+			s.additionalItems.validate(element, result)
+		}
+	}
+	if s.contains != nil {
+		count := 0
+		for _, element := range elements {
+			if s.contains.valid(element) {
+				count++
+			}
+		}
+		minimum := 1
+		if s.minContains != nil {
+			minimum = *s.minContains
+		}
+		if count < minimum {
+			s.fail(node, result, "contains", "array contains %d matching items, expected at least %d", count, minimum)
+		}
+		if s.maxContains != nil && count > *s.maxContains {
+			s.fail(node, result, "maxContains", "array contains %d matching items, expected at most %d", count, *s.maxContains)
+		}
+	}
+}
+
+func (s *schema) validateObject(node *ajson.Node, result *[]Violation) {
+	keys := node.Keys()
+	if s.maxProperties != nil && len(keys) > *s.maxProperties {
+		s.fail(node, result, "maxProperties", "object has %d properties, expected at most %d", len(keys), *s.maxProperties)
+	}
+	if s.minProperties != nil && len(keys) < *s.minProperties {
+		s.fail(node, result, "minProperties", "object has %d properties, expected at least %d", len(keys), *s.minProperties)
+	}
+	for _, name := range s.required {
+		if !node.HasKey(name) {
+			s.fail(node, result, "required", "missing required property '%s'", name)
+		}
+	}
+	for _, key := range keys {
+		for _, name := range s.dependentRequired[key] {
+			if !node.HasKey(name) {
+				s.fail(node, result, "dependentRequired", "missing property '%s', required by '%s'", name, key)
+			}
+		}
+		for _, name := range s.dependencies[key] {
+			if !node.HasKey(name) {
+				s.fail(node, result, "dependencies", "missing property '%s', required by '%s'", name, key)
+			}
+		}
+		if dependent, ok := s.dependentSchemas[key]; ok {
+			dependent.validate(node, result)
+		}
+	}
+	for _, key := range keys {
+		child := node.MustKey(key)
+		if s.propertyNames != nil && !s.propertyNames.valid(ajson.StringNode("", key)) {
+			s.fail(node, result, "propertyNames", "property name '%s' is not valid", key)
+		}
+		evaluated := false
+		if property, ok := s.properties[key]; ok {
+			property.validate(child, result)
+			evaluated = true
+		}
+		for _, property := range s.patternProperties {
+			if property.pattern.MatchString(key) {
+				property.schema.validate(child, result)
+				evaluated = true
+			}
+		}
+		if !evaluated && s.additionalProperties != nil {
+			if s.additionalProperties.boolean != nil && !*s.additionalProperties.boolean {
+				s.fail(node, result, "additionalProperties", "additional property '%s' is not allowed", key)
+			} else {
+				s.additionalProperties.validate(child, result)
+			}
+		}
+	}
+}
+
+func (s *schema) validateApplicators(node *ajson.Node, result *[]Violation) {
+	for _, item := range s.allOf {
+		item.validate(node, result)
+	}
+	if s.anyOf != nil {
+		matched := false
+		for _, item := range s.anyOf {
+			if item.valid(node) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			s.fail(node, result, "anyOf", "value does not match any of the schemas")
+		}
+	}
+	if s.oneOf != nil {
+		var matched []string
+		for i, item := range s.oneOf {
+			if item.valid(node) {
+				matched = append(matched, fmt.Sprint(i))
+			}
+		}
+		if len(matched) == 0 {
+			s.fail(node, result, "oneOf", "value does not match any of the schemas")
+		} else if len(matched) > 1 {
+			s.fail(node, result, "oneOf", "value matches more than one schema: %s", strings.Join(matched, ", "))
+		}
+	}
+	if s.not != nil && s.not.valid(node) {
+		s.fail(node, result, "not", "value should not match the schema")
+	}
+	if s.ifSchema != nil {
+		if s.ifSchema.valid(node) {
+			if s.thenSchema != nil {
+				s.thenSchema.validate(node, result)
+			}
+		} else if s.elseSchema != nil {
+			s.elseSchema.validate(node, result)
+		}
+	}
+}
+
+// valid returns true if the node is valid by the schema
+func (s *schema) valid(node *ajson.Node) bool {
+	var result []Violation
+	s.validate(node, &result)
+	return len(result) == 0
+}
+
+// fail appends the violation of the keyword
+func (s *schema) fail(node *ajson.Node, result *[]Violation, keyword string, format string, args ...interface{}) {
+	location := s.location
+	if keyword != "" {
+		location += "/" + keyword
+	}
+	*result = append(*result, Violation{
+		Path:    node.Path(),
+		Keyword: location,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// typeOf returns the JSON Schema type of the node
+func typeOf(node *ajson.Node) string {
+	switch node.Type() {
+	case ajson.Null:
+		return "null"
+	case ajson.Bool:
+		return "boolean"
+	case ajson.Numeric:
+		if value, err := node.GetNumeric(); err == nil && value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case ajson.String:
+		return "string"
+	case ajson.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// equal compares nodes by their values
+func equal(left, right *ajson.Node) bool {
+	if left.IsNumeric() && right.IsNumeric() {
+		return compare(left, right) == 0
+	}
+	result, err := left.Eq(right)
+	return err == nil && result
+}
+
+// compare compares numeric nodes: -1 if left < right, 0 if they are equal, and +1 if left > right
+func compare(left, right *ajson.Node) int {
+	if a, ok := rat(left); ok {
+		if b, ok := rat(right); ok {
+			return a.Cmp(b)
+		}
+	}
+	a, errA := left.GetBigFloat()
+	b, errB := right.GetBigFloat()
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return a.Cmp(b)
+}
+
+// multipleOf returns true if the value is a multiple of the divisor
+func multipleOf(value, divisor *ajson.Node) bool {
+	if a, ok := rat(value); ok {
+		if b, ok := rat(divisor); ok {
+			return a.Quo(a, b).IsInt()
+		}
+	}
+	a, errA := value.GetNumeric()
+	b, errB := divisor.GetNumeric()
+	if errA != nil || errB != nil {
+		return false
+	}
+	quotient := a / b
+	return !math.IsInf(quotient, 0) && quotient == math.Trunc(quotient)
+}
+
+// number returns the number as it is written in JSON
+func number(node *ajson.Node) string {
+	value, err := node.GetNumberString()
+	if err != nil {
+		return node.String()
+	}
+	return value
+}
@@ -0,0 +1,166 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		keywords []string
+	}{
+		{name: "true", schema: `true`, instance: `{"a": 1}`},
+		{name: "false", schema: `false`, instance: `1`, keywords: []string{"#"}},
+		{name: "type", schema: `{"type": "string"}`, instance: `"a"`},
+		{name: "type mismatch", schema: `{"type": "string"}`, instance: `1`, keywords: []string{"#/type"}},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, instance: `null`},
+		{name: "integer", schema: `{"type": "integer"}`, instance: `1.0`},
+		{name: "integer fraction", schema: `{"type": "integer"}`, instance: `1.5`, keywords: []string{"#/type"}},
+		{name: "number of integer", schema: `{"type": "number"}`, instance: `2`},
+		{name: "enum", schema: `{"enum": [1, "a", {"b": [null]}]}`, instance: `{"b": [null]}`},
+		{name: "enum number", schema: `{"enum": [1, 2]}`, instance: `1.0`},
+		{name: "enum mismatch", schema: `{"enum": [1, "a"]}`, instance: `"b"`, keywords: []string{"#/enum"}},
+		{name: "enum types", schema: `{"enum": [false]}`, instance: `0`, keywords: []string{"#/enum"}},
+		{name: "const", schema: `{"const": [1, 2]}`, instance: `[1, 2]`},
+		{name: "const mismatch", schema: `{"const": [1, 2]}`, instance: `[2, 1]`, keywords: []string{"#/const"}},
+		{name: "multipleOf", schema: `{"multipleOf": 0.01}`, instance: `19.99`},
+		{name: "multipleOf mismatch", schema: `{"multipleOf": 0.01}`, instance: `19.999`, keywords: []string{"#/multipleOf"}},
+		{name: "multipleOf large", schema: `{"multipleOf": 3}`, instance: `1e308`, keywords: []string{"#/multipleOf"}},
+		{name: "maximum", schema: `{"maximum": 10}`, instance: `10`},
+		{name: "maximum mismatch", schema: `{"maximum": 10}`, instance: `10.5`, keywords: []string{"#/maximum"}},
+		{name: "exclusiveMaximum", schema: `{"exclusiveMaximum": 10}`, instance: `10`, keywords: []string{"#/exclusiveMaximum"}},
+		{name: "minimum", schema: `{"minimum": 9007199254740993}`, instance: `9007199254740992`, keywords: []string{"#/minimum"}},
+		{name: "exclusiveMinimum", schema: `{"exclusiveMinimum": 0}`, instance: `0`, keywords: []string{"#/exclusiveMinimum"}},
+		{name: "numbers ignore strings", schema: `{"minimum": 1, "multipleOf": 2}`, instance: `"a"`},
+		{name: "maxLength", schema: `{"maxLength": 2}`, instance: `"日本"`},
+		{name: "maxLength mismatch", schema: `{"maxLength": 2}`, instance: `"abc"`, keywords: []string{"#/maxLength"}},
+		{name: "minLength", schema: `{"minLength": 2}`, instance: `"a"`, keywords: []string{"#/minLength"}},
+		{name: "pattern", schema: `{"pattern": "^a+$"}`, instance: `"aaa"`},
+		{name: "pattern unanchored", schema: `{"pattern": "b"}`, instance: `"abc"`},
+		{name: "pattern mismatch", schema: `{"pattern": "^a+$"}`, instance: `"ab"`, keywords: []string{"#/pattern"}},
+		{name: "format", schema: `{"format": "ipv4"}`, instance: `"127.0.0.1"`},
+		{name: "format mismatch", schema: `{"format": "ipv4"}`, instance: `"::1"`, keywords: []string{"#/format"}},
+		{name: "format unknown", schema: `{"format": "color"}`, instance: `"red"`},
+		{name: "items", schema: `{"items": {"type": "integer"}}`, instance: `[1, "2", 3.5]`, keywords: []string{"#/items/type", "#/items/type"}},
+		{name: "prefixItems", schema: `{"prefixItems": [{"type": "string"}], "items": false}`, instance: `["a", 1]`, keywords: []string{"#/items"}},
+		{name: "draft-07 items", schema: `{"items": [{"type": "string"}], "additionalItems": {"type": "number"}}`, instance: `["a", 1, "b"]`, keywords: []string{"#/additionalItems/type"}},
+		{name: "additionalItems ignored", schema: `{"items": {}, "additionalItems": false}`, instance: `[1, 2]`},
+		{name: "maxItems", schema: `{"maxItems": 1}`, instance: `[1, 2]`, keywords: []string{"#/maxItems"}},
+		{name: "minItems", schema: `{"minItems": 1}`, instance: `[]`, keywords: []string{"#/minItems"}},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, instance: `[1, {"a": 1}, {"a": 2}]`},
+		{name: "uniqueItems mismatch", schema: `{"uniqueItems": true}`, instance: `[{"a": 1}, 2, {"a": 1.0}]`, keywords: []string{"#/uniqueItems"}},
+		{name: "contains", schema: `{"contains": {"const": 2}}`, instance: `[1, 2]`},
+		{name: "contains mismatch", schema: `{"contains": {"const": 2}}`, instance: `[1, 3]`, keywords: []string{"#/contains"}},
+		{name: "minContains", schema: `{"contains": {"const": 2}, "minContains": 2}`, instance: `[2, 1]`, keywords: []string{"#/contains"}},
+		{name: "maxContains", schema: `{"contains": {"const": 2}, "maxContains": 1}`, instance: `[2, 2]`, keywords: []string{"#/maxContains"}},
+		{name: "properties", schema: `{"properties": {"a": {"type": "string"}, "b": {"type": "number"}}}`, instance: `{"a": 1, "b": 2, "c": 3}`, keywords: []string{"#/properties/a/type"}},
+		{name: "patternProperties", schema: `{"patternProperties": {"^x-": {"type": "string"}}}`, instance: `{"x-a": "1", "x-b": 2, "y": 3}`, keywords: []string{"#/patternProperties/^x-/type"}},
+		{name: "additionalProperties", schema: `{"properties": {"a": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": false}`, instance: `{"a": 1, "x-b": 2, "c": 3}`, keywords: []string{"#/additionalProperties"}},
+		{name: "additionalProperties schema", schema: `{"additionalProperties": {"type": "string"}}`, instance: `{"a": "1", "b": 2}`, keywords: []string{"#/additionalProperties/type"}},
+		{name: "propertyNames", schema: `{"propertyNames": {"maxLength": 2}}`, instance: `{"ab": 1, "abc": 2}`, keywords: []string{"#/propertyNames"}},
+		{name: "required", schema: `{"required": ["a", "b", "c"]}`, instance: `{"a": null}`, keywords: []string{"#/required", "#/required"}},
+		{name: "required ignores arrays", schema: `{"required": ["a"]}`, instance: `[]`},
+		{name: "dependentRequired", schema: `{"dependentRequired": {"a": ["b"]}}`, instance: `{"a": 1}`, keywords: []string{"#/dependentRequired"}},
+		{name: "dependentSchemas", schema: `{"dependentSchemas": {"a": {"required": ["b"]}}}`, instance: `{"a": 1}`, keywords: []string{"#/dependentSchemas/a/required"}},
+		{name: "dependencies", schema: `{"dependencies": {"a": ["b"], "c": {"maxProperties": 1}}}`, instance: `{"a": 1, "c": 2}`, keywords: []string{"#/dependencies", "#/dependencies/c/maxProperties"}},
+		{name: "maxProperties", schema: `{"maxProperties": 1}`, instance: `{"a": 1, "b": 2}`, keywords: []string{"#/maxProperties"}},
+		{name: "minProperties", schema: `{"minProperties": 1}`, instance: `{}`, keywords: []string{"#/minProperties"}},
+		{name: "allOf", schema: `{"allOf": [{"type": "number"}, {"minimum": 2}]}`, instance: `1`, keywords: []string{"#/allOf/1/minimum"}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"minimum": 2}]}`, instance: `3`},
+		{name: "anyOf mismatch", schema: `{"anyOf": [{"type": "string"}, {"minimum": 2}]}`, instance: `1`, keywords: []string{"#/anyOf"}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "string"}, {"minimum": 2}]}`, instance: `3`},
+		{name: "oneOf none", schema: `{"oneOf": [{"type": "string"}, {"minimum": 2}]}`, instance: `1`, keywords: []string{"#/oneOf"}},
+		{name: "oneOf many", schema: `{"oneOf": [{"type": "number"}, {"minimum": 2}]}`, instance: `3`, keywords: []string{"#/oneOf"}},
+		{name: "not", schema: `{"not": {"type": "null"}}`, instance: `null`, keywords: []string{"#/not"}},
+		{name: "if then", schema: `{"if": {"minimum": 10}, "then": {"multipleOf": 10}, "else": {"maximum": 5}}`, instance: `15`, keywords: []string{"#/then/multipleOf"}},
+		{name: "if else", schema: `{"if": {"minimum": 10}, "then": {"multipleOf": 10}, "else": {"maximum": 5}}`, instance: `7`, keywords: []string{"#/else/maximum"}},
+		{name: "ref", schema: `{"$defs": {"a": {"type": "integer"}}, "properties": {"a": {"$ref": "#/$defs/a"}}}`, instance: `{"a": "1"}`, keywords: []string{"#/$defs/a/type"}},
+		{name: "ref with siblings", schema: `{"$defs": {"a": {"type": "integer"}}, "$ref": "#/$defs/a", "minimum": 5}`, instance: `1.5`, keywords: []string{"#/$defs/a/type", "#/minimum"}},
+		{name: "ref draft-07", schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {"a": {"type": "integer"}}, "$ref": "#/definitions/a", "minimum": 5}`, instance: `1.5`, keywords: []string{"#/definitions/a/type"}},
+		{name: "ref escaped", schema: `{"$defs": {"a/b": {"type": "integer"}, "c%d": {"type": "string"}}, "items": [{"$ref": "#/$defs/a~1b"}, {"$ref": "#/$defs/c%25d"}]}`, instance: `["1", 2]`, keywords: []string{"#/$defs/a~1b/type", "#/$defs/c%d/type"}},
+		{name: "ref anchor", schema: `{"$defs": {"a": {"$anchor": "item", "type": "integer"}}, "items": {"$ref": "#item"}}`, instance: `[1, "2"]`, keywords: []string{"#/$defs/a/type"}},
+		{name: "ref draft-07 id", schema: `{"definitions": {"a": {"$id": "#item", "type": "integer"}}, "items": {"$ref": "#item"}}`, instance: `[1, "2"]`, keywords: []string{"#/definitions/a/type"}},
+		{name: "ref with id", schema: `{"$id": "https://example.com/schema.json", "$defs": {"a": {"type": "integer"}}, "items": {"$ref": "https://example.com/schema.json#/$defs/a"}}`, instance: `["1"]`, keywords: []string{"#/$defs/a/type"}},
+		{name: "recursive ref", schema: `{"type": "object", "properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`, instance: `{"name": "a", "children": [{"name": "b", "children": [{"name": 1}]}]}`, keywords: []string{"#/properties/name/type"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compiled, err := Compile(ajson.Must(ajson.Unmarshal([]byte(test.schema))))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			violations := compiled.Validate(ajson.Must(ajson.Unmarshal([]byte(test.instance))))
+			if len(violations) != len(test.keywords) {
+				t.Fatalf("Validate() = %v, expected violations of %v", violations, test.keywords)
+			}
+			for i, violation := range violations {
+				if violation.Keyword != test.keywords[i] {
+					t.Errorf("Validate() keyword = %s, expected %s", violation.Keyword, test.keywords[i])
+				}
+				if violation.Message == "" {
+					t.Errorf("Validate() empty message")
+				}
+			}
+			if compiled.Valid(ajson.Must(ajson.Unmarshal([]byte(test.instance)))) != (len(test.keywords) == 0) {
+				t.Errorf("Valid() wrong result")
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_path(t *testing.T) {
+	compiled := MustCompile(ajson.Must(ajson.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["items"],
+		"properties": {
+			"items": {"type": "array", "items": {"$ref": "#/$defs/item"}}
+		},
+		"$defs": {
+			"item": {
+				"type": "object",
+				"required": ["id", "price"],
+				"properties": {
+					"id": {"type": "integer"},
+					"price": {"type": "number", "minimum": 0}
+				}
+			}
+		}
+	}`))))
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"items": [{"id": 1, "price": 10}, {"id": "2", "price": -1}, {"price": 1}]}`)))
+	expected := []Violation{
+		{Path: "$['items'][1]['id']", Keyword: "#/$defs/item/properties/id/type", Message: "expected integer, got string"},
+		{Path: "$['items'][1]['price']", Keyword: "#/$defs/item/properties/price/minimum", Message: "-1 is less than minimum 0"},
+		{Path: "$['items'][2]", Keyword: "#/$defs/item/required", Message: "missing required property 'id'"},
+	}
+	violations := compiled.Validate(root)
+	if len(violations) != len(expected) {
+		t.Fatalf("Validate() = %v, expected %v", violations, expected)
+	}
+	for i := range expected {
+		if violations[i] != expected[i] {
+			t.Errorf("Validate() = %v, expected %v", violations[i], expected[i])
+		}
+	}
+	if result := violations[1].String(); result != "$['items'][1]['price']: -1 is less than minimum 0 (#/$defs/item/properties/price/minimum)" {
+		t.Errorf("String() = %s", result)
+	}
+}
+
+func TestSchema_Validate_dirty(t *testing.T) {
+	compiled := MustCompile(ajson.Must(ajson.Unmarshal([]byte(`{"properties": {"a": {"type": "integer"}, "b": {"maxLength": 1}}}`))))
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"a": 1}`)))
+	if err := root.MustKey("a").SetNumeric(1.5); err != nil {
+		t.Fatalf("SetNumeric() error = %v", err)
+	}
+	if err := root.AppendObject("b", ajson.StringNode("", "ab")); err != nil {
+		t.Fatalf("AppendObject() error = %v", err)
+	}
+	violations := compiled.Validate(root)
+	if len(violations) != 2 || violations[0].Path != "$['a']" || violations[1].Path != "$['b']" {
+		t.Errorf("Validate() = %v", violations)
+	}
+}
@@ -0,0 +1,346 @@
+package ajson
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	. "github.com/spyzhov/ajson/internal"
+)
+
+// qt is the action code of the closing quote, copy from `internal/state.go:144`
+const qt States = -4
+
+// tokenizerBufferSize is the size of chunks, which Tokenizer reads from the input stream
+const tokenizerBufferSize = 4096
+
+// Handler receives events from the Tokenizer. Each method gets the offset of the first byte of the event in the input stream.
+//
+// Key receives already unquoted key of the Object.
+// Value receives the type and the source of the scalar value (Null, Numeric, String or Bool), string values are still quoted.
+// The source slice is valid only until the method returns, copy it to keep.
+//
+// If any method returns an error, tokenizing stops and the error is returned from Tokenizer.Tokenize.
+type Handler interface {
+	BeginObject(offset int) error
+	EndObject(offset int) error
+	BeginArray(offset int) error
+	EndArray(offset int) error
+	Key(key string, offset int) error
+	Value(_type NodeType, source []byte, offset int) error
+}
+
+// Tokenizer reads JSON values from an input stream and emits the events of its structure to the Handler,
+// without creating the nodes. It uses the same validation rules as Unmarshal and returns the same errors,
+// with the line, the column, the path, the expectation and the snippet of the wrong symbol.
+type Tokenizer struct {
+	reader  *bufio.Reader
+	offset  int
+	line    int
+	column  int
+	newline bool
+	recent  []byte // the end of the current line, to show the snippet of the error
+	value   bool   // the value token is being read
+	token   []byte
+	stack   []level
+}
+
+// level is the container, which is being tokenized
+type level struct {
+	object bool
+	key    string // the last key of the object
+	size   int    // the count of started elements of the array
+}
+
+// NewTokenizer returns a new Tokenizer that reads from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{
+		reader: bufio.NewReaderSize(r, tokenizerBufferSize),
+	}
+}
+
+// Offset returns the count of bytes, which were read from the input stream.
+func (t *Tokenizer) Offset() int {
+	return t.offset
+}
+
+// Line returns the number of the current line in the input stream, starting from 1.
+func (t *Tokenizer) Line() int {
+	return t.line + 1
+}
+
+// SkipLine discards the rest of the current line in the input stream.
+// It can be used to continue reading of JSON Lines after an error.
+func (t *Tokenizer) SkipLine() error {
+	for !t.newline {
+		if _, err := t.read(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read returns the next byte of the input stream and counts the lines
+func (t *Tokenizer) read() (c byte, err error) {
+	c, err = t.reader.ReadByte()
+	if err != nil {
+		return
+	}
+	t.offset++
+	if t.newline {
+		t.line++
+		t.column = 0
+		t.recent = t.recent[:0]
+	}
+	t.newline = c == skipN
+	if utf8.RuneStart(c) {
+		t.column++
+	}
+	t.recent = append(t.recent, c)
+	if len(t.recent) > 4*snippetWidth {
+		t.recent = append(t.recent[:0], t.recent[len(t.recent)-2*snippetWidth:]...)
+	}
+	return
+}
+
+// Tokenize reads the next JSON value from its input and emits all of its events to the handler.
+//
+// Tokenize returns io.EOF if there are no more values in the input stream.
+func (t *Tokenizer) Tokenize(handler Handler) (err error) {
+	var (
+		c     byte
+		index int
+		start int
+		class Classes
+		last  States
+		state = GO
+		key   bool
+	)
+	t.stack = t.stack[:0]
+	t.value = false
+
+	for {
+		index = t.offset
+		c, err = t.read()
+		if err == io.EOF {
+			return t.eof(handler, state, start)
+		}
+		if err != nil {
+			return err
+		}
+
+		if c >= 128 {
+			class = C_ETC
+		} else {
+			class = AsciiClasses[c]
+		}
+		if class == __ {
+			return t.errorAt(index, c, state)
+		}
+		last = state
+		state = StateTransitionTable[last][class]
+		if state == __ {
+			return t.errorAt(index, c, last)
+		}
+
+		if last >= ST { // region Token
+			if state >= ST {
+				t.token = append(t.token, c)
+				continue
+			}
+			part := last < MI || last > E3 // the last symbol of string, true, false or null
+			if part {
+				t.token = append(t.token, c)
+			}
+			if last <= U4 && t.object() && !key {
+				// Detected: Key
+				value, ok := unquote(t.token, quotes)
+				if !ok {
+					return t.errorAt(index, c, last)
+				}
+				if err = handler.Key(value, start); err != nil {
+					return err
+				}
+				t.stack[len(t.stack)-1].key = value
+				key = true
+				state = CO
+				continue
+			}
+			if err = handler.Value(tokenType(last), t.token, start); err != nil {
+				return err
+			}
+			key = false
+			t.value = false
+			last = OK
+			if len(t.stack) == 0 {
+				if !part && state < OK { // the symbol isn't part of the current value
+					t.offset--
+					_ = t.reader.UnreadByte()
+				}
+				return nil
+			}
+			if part || state >= OK {
+				state = OK
+				continue
+			}
+		} // endregion Token
+
+		if state >= GO {
+			if state >= ST { // start of the token
+				start = index
+				t.token = append(t.token[:0], c)
+				if !t.object() || key {
+					t.element()
+					t.value = true
+				}
+			}
+			continue
+		}
+
+		// region Action
+		switch state {
+		case ec: /* empty } */
+			if key {
+				return t.errorAt(index, c, last)
+			}
+			fallthrough
+		case cc: /* } */
+			if !t.object() {
+				return t.errorAt(index, c, last)
+			}
+			t.stack = t.stack[:len(t.stack)-1]
+			err = handler.EndObject(index)
+		case bc: /* ] */
+			if len(t.stack) == 0 || t.object() {
+				return t.errorAt(index, c, last)
+			}
+			t.stack = t.stack[:len(t.stack)-1]
+			err = handler.EndArray(index)
+		case co: /* { */
+			t.element()
+			t.stack = append(t.stack, level{object: true})
+			key = false
+			err = handler.BeginObject(index)
+			state = OB
+		case bo: /* [ */
+			t.element()
+			t.stack = append(t.stack, level{})
+			key = false
+			err = handler.BeginArray(index)
+			state = AR
+		case cm: /* , */
+			if len(t.stack) == 0 {
+				return t.errorAt(index, c, last)
+			}
+			if t.object() {
+				state = KE
+			} else {
+				state = VA
+			}
+		case cl: /* : */
+			if !t.object() || !key {
+				return t.errorAt(index, c, last)
+			}
+			state = VA
+		default: /* syntax error */
+			return t.errorAt(index, c, last)
+		}
+		if err != nil {
+			return err
+		}
+		if state < GO {
+			if len(t.stack) == 0 {
+				return nil
+			}
+			state = OK
+		}
+		// endregion Action
+	}
+}
+
+// eof finishes the current value on the end of the input stream
+func (t *Tokenizer) eof(handler Handler, state States, start int) error {
+	if state == GO && len(t.stack) == 0 {
+		return io.EOF
+	}
+	if state == ZE || state == IN || state == FR || state == E3 {
+		if len(t.stack) == 0 {
+			return handler.Value(Numeric, t.token, start)
+		}
+		// the number is complete, but its container is not
+		state, t.value = OK, false
+	}
+	err := Error{Type: UnexpectedEOF, Index: t.offset, Line: t.Line(), Column: t.column + 1}
+	if t.newline {
+		err.Line, err.Column = err.Line+1, 1
+	}
+	return t.context(err, state)
+}
+
+// errorAt returns the WrongSymbol error of the last read symbol
+func (t *Tokenizer) errorAt(index int, symbol byte, state States) error {
+	return t.context(Error{Type: WrongSymbol, Index: index, Char: symbol, Line: t.Line(), Column: t.column}, state)
+}
+
+// context fills the snippet of the current line, the path of the current value and the expectation of the state,
+// same as Unmarshal does
+func (t *Tokenizer) context(err Error, state States) error {
+	data := append([]byte{}, t.recent...)
+	index := len(data) - (t.offset - err.Index)
+	if next, _ := t.reader.Peek(snippetWidth); len(next) != 0 {
+		data = append(data, next...)
+	}
+	_, _, err.Snippet = position(data, index)
+	err.Path = t.path()
+	container := Null
+	if len(t.stack) != 0 {
+		container = Array
+		if t.object() {
+			container = Object
+		}
+	}
+	err.Expected = expectation(state, container)
+	return err
+}
+
+// path returns the JSONPath of the current value, or of the current container, if the value is not started
+func (t *Tokenizer) path() string {
+	result := "$"
+	for i, current := range t.stack {
+		if i == len(t.stack)-1 && !t.value {
+			break
+		}
+		if current.object {
+			result += "['" + current.key + "']"
+		} else {
+			result += "[" + strconv.Itoa(current.size-1) + "]"
+		}
+	}
+	return result
+}
+
+// element counts the started element of the current array
+func (t *Tokenizer) element() {
+	if len(t.stack) != 0 && !t.object() {
+		t.stack[len(t.stack)-1].size++
+	}
+}
+
+// object returns true if the current container is an Object
+func (t *Tokenizer) object() bool {
+	return len(t.stack) != 0 && t.stack[len(t.stack)-1].object
+}
+
+// tokenType returns the type of the value by the last state of its token
+func tokenType(state States) NodeType {
+	switch {
+	case state >= ST && state <= U4:
+		return String
+	case state >= MI && state <= E3:
+		return Numeric
+	case state >= T1 && state <= F4:
+		return Bool
+	}
+	return Null
+}
@@ -0,0 +1,226 @@
+package ajson
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) BeginObject(offset int) error {
+	r.events = append(r.events, fmt.Sprintf("{@%d", offset))
+	return nil
+}
+
+func (r *recorder) EndObject(offset int) error {
+	r.events = append(r.events, fmt.Sprintf("}@%d", offset))
+	return nil
+}
+
+func (r *recorder) BeginArray(offset int) error {
+	r.events = append(r.events, fmt.Sprintf("[@%d", offset))
+	return nil
+}
+
+func (r *recorder) EndArray(offset int) error {
+	r.events = append(r.events, fmt.Sprintf("]@%d", offset))
+	return nil
+}
+
+func (r *recorder) Key(key string, offset int) error {
+	r.events = append(r.events, fmt.Sprintf("key(%s)@%d", key, offset))
+	return nil
+}
+
+func (r *recorder) Value(_type NodeType, source []byte, offset int) error {
+	r.events = append(r.events, fmt.Sprintf("value(%d:%s)@%d", _type, source, offset))
+	return nil
+}
+
+type counter struct {
+	recorder
+	values int
+}
+
+func (c *counter) Value(NodeType, []byte, int) error {
+	c.values++
+	if c.values > 2 {
+		return errors.New("too many values")
+	}
+	return nil
+}
+
+func TestTokenizer_Tokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "null", input: ` null `, expected: []string{"value(0:null)@1"}},
+		{name: "numeric", input: `-1.5e3`, expected: []string{"value(1:-1.5e3)@0"}},
+		{name: "string", input: `"a\"b"`, expected: []string{`value(2:"a\"b")@0`}},
+		{name: "bool", input: `false`, expected: []string{"value(3:false)@0"}},
+		{name: "empty array", input: `[ ]`, expected: []string{"[@0", "]@2"}},
+		{name: "empty object", input: `{}`, expected: []string{"{@0", "}@1"}},
+		{
+			name:  "array",
+			input: `[1, "2", [true]]`,
+			expected: []string{
+				"[@0",
+				"value(1:1)@1",
+				`value(2:"2")@4`,
+				"[@9",
+				"value(3:true)@10",
+				"]@14",
+				"]@15",
+			},
+		},
+		{
+			name:  "object",
+			input: `{"a": {"b\n": 12}, "c": [null]}`,
+			expected: []string{
+				"{@0",
+				"key(a)@1",
+				"{@6",
+				"key(b\n)@7",
+				"value(1:12)@14",
+				"}@16",
+				"key(c)@19",
+				"[@24",
+				"value(0:null)@25",
+				"]@29",
+				"}@30",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := new(recorder)
+			err := NewTokenizer(iotest.OneByteReader(strings.NewReader(test.input))).Tokenize(handler)
+			if err != nil {
+				t.Fatalf("Tokenize() error = %v", err)
+			}
+			if !reflect.DeepEqual(handler.events, test.expected) {
+				t.Errorf("Tokenize() events = %q, expected %q", handler.events, test.expected)
+			}
+		})
+	}
+}
+
+func TestTokenizer_Tokenize_corrupted(t *testing.T) {
+	tests := []string{
+		`[1,]`,
+		`{"foo":"bar",}`,
+		`{"foo" 1}`,
+		`[1}`,
+		`{"a":1]`,
+		`[[1]`,
+		`nul`,
+		`01`,
+		`{"foo": [1, 2`,
+		`{"foo": [1, 2, x]}`,
+		"{\n\"a\": {\"b\": [1, 2,\n  tru]}}",
+		`[{"b": 1}, {"c": x`,
+		`{"a": -}`,
+		`["\x"]`,
+		`[é]`,
+		"[1,\n",
+		strings.Repeat(" ", 100) + `[1, 2, x` + strings.Repeat(" ", 100) + "1",
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			err := NewTokenizer(iotest.OneByteReader(strings.NewReader(test))).Tokenize(new(recorder))
+			if _, ok := err.(Error); !ok {
+				t.Errorf("Tokenize() expected Error, got %v", err)
+			}
+			_, expected := Unmarshal([]byte(test))
+			if !reflect.DeepEqual(err, expected) {
+				t.Errorf("Tokenize() error = %#v\nUnmarshal() error = %#v", err, expected)
+			}
+		})
+	}
+}
+
+func TestTokenizer_Tokenize_error(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader("[1]\n{\"a\": [1,\n\t2}"))
+	if err := tokenizer.Tokenize(new(recorder)); err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+	err := tokenizer.Tokenize(new(recorder))
+	expected := "wrong symbol '}' at 16 on line 3, column 3 in $['a']: expected ',' or ']'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Tokenize() error = %v, expected %s", err, expected)
+	}
+	if e, ok := err.(Error); !ok || e.Snippet != "\t2}\n\t ^" {
+		t.Errorf("Tokenize() snippet = %q", e.Snippet)
+	}
+}
+
+func TestTokenizer_Tokenize_handler(t *testing.T) {
+	handler := new(counter)
+	err := NewTokenizer(strings.NewReader(`[1, 2, 3, 4]`)).Tokenize(handler)
+	if err == nil || err.Error() != "too many values" {
+		t.Errorf("Tokenize() expected handler error, got %v", err)
+	}
+}
+
+func TestTokenizer_Tokenize_stream(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader(`1 [2]`))
+	handler := new(recorder)
+	for i := 0; i < 2; i++ {
+		if err := tokenizer.Tokenize(handler); err != nil {
+			t.Fatalf("Tokenize() error = %v", err)
+		}
+	}
+	if err := tokenizer.Tokenize(handler); err != io.EOF {
+		t.Errorf("Tokenize() expected io.EOF, got %v", err)
+	}
+	expected := []string{"value(1:1)@0", "[@2", "value(1:2)@3", "]@4"}
+	if !reflect.DeepEqual(handler.events, expected) {
+		t.Errorf("Tokenize() events = %q, expected %q", handler.events, expected)
+	}
+}
+
+type priceCounter struct {
+	recorder
+	price bool
+	count int
+	sum   float64
+}
+
+func (p *priceCounter) Key(key string, _ int) error {
+	p.price = key == "price"
+	return nil
+}
+
+func (p *priceCounter) Value(_type NodeType, source []byte, _ int) error {
+	if p.price && _type == Numeric {
+		node, err := Unmarshal(source)
+		if err != nil {
+			return err
+		}
+		p.count++
+		p.sum += node.MustNumeric()
+	}
+	p.price = false
+	return nil
+}
+
+func ExampleTokenizer() {
+	data := `{"store": {"book": [{"title": "Moby Dick", "price": 8.99}, {"title": "Sword of Honour", "price": 12.99}], "bicycle": {"price": 19.95}}}`
+	handler := new(priceCounter)
+	err := NewTokenizer(strings.NewReader(data)).Tokenize(handler)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Count: %d, Sum: %.2f", handler.count, handler.sum)
+	// Output:
+	// Count: 3, Sum: 41.93
+}
@@ -0,0 +1,315 @@
+package ajson
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// trivia is the layout of the node, parsed with the comments: the whitespaces and comments around the node, so the
+// changes can be spliced into the original source.
+type trivia struct {
+	data   *[]byte
+	parent *Node
+	start  int    // start of the leading whitespaces and comments
+	key    int    // start of the key in the object, or of the value
+	colon  int    // end of the key in the object, or the start of the value
+	value  [2]int // borders of the value
+	comma  int    // index of the comma after the value, or -1
+	end    int    // end of the trailing comments on the same line
+	tail   int    // start of the whitespaces and comments before the closing bracket of the container
+}
+
+// Comments returns the comments before and after the node, if the data was parsed with the KeepComments option.
+// Comments are returned as is, e.g.: `// line` or `/* block */`.
+//
+// Comments, which follow the value on the same line, are trailing, all others are leading comments of the next node.
+func (n *Node) Comments() (leading []string, trailing []string) {
+	if n.trivia == nil {
+		return nil, nil
+	}
+	data := *n.trivia.data
+	leading = comments(data[n.trivia.start:n.trivia.key])
+	if n.trivia.comma < 0 {
+		trailing = comments(data[n.trivia.value[1]:n.trivia.end])
+	} else {
+		trailing = append(comments(data[n.trivia.value[1]:n.trivia.comma]), comments(data[n.trivia.comma+1:n.trivia.end])...)
+	}
+	return leading, trailing
+}
+
+// attach records the layout of the parsed node and all its children.
+func attach(node *Node, layout *trivia) {
+	node.trivia = layout
+	if !node.isContainer() {
+		return
+	}
+	data := *node.data
+	cursor := node.borders[0] + 1
+	for _, child := range node.ordered() {
+		current := &trivia{data: node.data, parent: node, start: cursor, comma: -1, value: child.borders}
+		current.key = skipTrivia(data, cursor)
+		current.colon = current.key
+		if node.IsObject() {
+			current.colon = keyEnd(data, current.key)
+		}
+		next := skipTrivia(data, current.value[1])
+		if next < len(data) && data[next] == coma {
+			current.comma = next
+			current.end = sameLine(data, next+1)
+		} else {
+			current.end = sameLine(data, current.value[1])
+		}
+		cursor = current.end
+		attach(child, current)
+	}
+	layout.tail = cursor
+}
+
+// ordered returns children of the container in order of parsing or appending
+func (n *Node) ordered() []*Node {
+	result := make([]*Node, 0, len(n.children))
+	if n.IsObject() {
+		for _, key := range n.keys {
+			result = append(result, n.children[key])
+		}
+	} else {
+		for i := 0; i < len(n.children); i++ {
+			result = append(result, n.children[strconv.Itoa(i)])
+		}
+	}
+	return result
+}
+
+// spliced checks if the changed container is still bound to the data, so the changes can be spliced into it.
+func (n *Node) spliced() bool {
+	return n.trivia != nil && n.isContainer() && n.ready() && n.data == n.trivia.data && n.borders == n.trivia.value
+}
+
+// original checks if the child still has its own place in the source of the container.
+func (n *Node) original(child *Node) bool {
+	return child.trivia != nil && child.trivia.parent == n
+}
+
+// splice writes the changed container, parsed with the comments: untouched parts are written from the source as is,
+// and only changed, added and removed elements are spliced into it.
+func (s *encodeState) splice(node *Node, depth int) (err error) {
+	data := *node.data
+	children := node.ordered()
+	var reference *trivia // separator of the keys of the new elements is copied from the nearest original one
+	for _, child := range children {
+		if node.original(child) {
+			reference = child.trivia
+			break
+		}
+	}
+	opening, separator := node.leads(children)
+	_ = s.writer.WriteByte(data[node.borders[0]])
+	newline := false // the previous element ends with the line comment
+	for i, child := range children {
+		last := i == len(children)-1
+		lead := separator
+		if i == 0 {
+			lead = opening
+		}
+		if !node.original(child) {
+			if newline && !startsLine(lead) {
+				_ = s.writer.WriteByte(skipN)
+			}
+			_, _ = s.writer.Write(lead)
+			if node.IsObject() {
+				s.quote(*child.key)
+				_, _ = s.writer.Write(keySeparator(reference))
+			}
+			if err = s.encode(child, depth+1); err != nil {
+				return err
+			}
+			if !last {
+				_ = s.writer.WriteByte(coma)
+			}
+			newline = false
+			continue
+		}
+		place := child.trivia
+		reference = place
+		if own := data[place.start:place.key]; (place.start == node.borders[0]+1) == (i == 0) || !blank(own) {
+			// the element keeps its own leading whitespaces and comments, if it is still on its place
+			lead = own
+		}
+		if newline && !startsLine(lead) {
+			_ = s.writer.WriteByte(skipN)
+		}
+		_, _ = s.writer.Write(lead)
+		_, _ = s.writer.Write(data[place.key:place.value[0]])
+		if !child.dirty && child.data == node.data && child.borders == place.value {
+			_, _ = s.writer.Write(data[place.value[0]:place.value[1]])
+		} else if err = s.encode(child, depth+1); err != nil {
+			return err
+		}
+		if place.comma < 0 {
+			if !last {
+				_ = s.writer.WriteByte(coma)
+			}
+			_, _ = s.writer.Write(data[place.value[1]:place.end])
+		} else {
+			_, _ = s.writer.Write(data[place.value[1]:place.comma])
+			if !last || place.end == node.trivia.tail {
+				// the trailing comma is kept, if it was there
+				_ = s.writer.WriteByte(coma)
+			}
+			_, _ = s.writer.Write(data[place.comma+1 : place.end])
+		}
+		newline = bytes.Contains(data[place.value[1]:place.end], []byte("//"))
+	}
+	tail := data[node.trivia.tail:node.borders[1]]
+	if newline && !startsLine(tail) {
+		_ = s.writer.WriteByte(skipN)
+	}
+	_, _ = s.writer.Write(tail)
+	return nil
+}
+
+// leads returns the leading whitespaces of the first element of the container and of the elements after the comma,
+// taken from the source: the first one is after the opening bracket, the other one is before any original element,
+// which is not the first one.
+func (n *Node) leads(children []*Node) (opening []byte, separator []byte) {
+	data := *n.data
+	opening = indent(data[n.borders[0]+1 : skipTrivia(data, n.borders[0]+1)])
+	separator = opening
+	for _, child := range children {
+		if n.original(child) && child.trivia.start != n.borders[0]+1 {
+			separator = indent(data[child.trivia.start:child.trivia.key])
+			break
+		}
+	}
+	return opening, separator
+}
+
+// startsLine checks if the data starts with the line break
+func startsLine(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{skipN}) || bytes.HasPrefix(data, []byte{skipR, skipN})
+}
+
+// keySeparator returns the separator of the key for the new element of the object, same as the reference has.
+func keySeparator(reference *trivia) []byte {
+	if reference == nil {
+		return []byte{colon}
+	}
+	data := *reference.data
+	separator := data[reference.colon:reference.value[0]]
+	if bytes.IndexByte(separator, division) >= 0 || bytes.IndexByte(separator, colon) < 0 {
+		return []byte{colon}
+	}
+	return separator
+}
+
+// indent returns the whitespaces of the last line of the leading trivia, without comments.
+func indent(lead []byte) []byte {
+	if i := bytes.LastIndexByte(lead, skipN); i >= 0 {
+		if i > 0 && lead[i-1] == skipR {
+			i--
+		}
+		lead = lead[i:]
+	}
+	lead = lead[:len(lead)-len(bytes.TrimLeft(lead, " \t\r\n"))]
+	return append([]byte{}, lead...)
+}
+
+// blank checks if the data contains only whitespaces
+func blank(data []byte) bool {
+	return len(bytes.TrimLeft(data, " \t\r\n")) == 0
+}
+
+// skipTrivia returns the index of the next token after the whitespaces and comments.
+func skipTrivia(data []byte, index int) int {
+	for index < len(data) {
+		switch data[index] {
+		case skipS, skipN, skipR, skipT:
+			index++
+		case division:
+			end, err := skipComment(data, index)
+			if err != nil {
+				return index
+			}
+			index = end
+		default:
+			if size := json5Space(data, index); size > 0 {
+				index += size
+				continue
+			}
+			return index
+		}
+	}
+	return index
+}
+
+// sameLine returns the end of the whitespaces and comments on the same line, if the line ends after them.
+func sameLine(data []byte, index int) int {
+	end := index
+	for end < len(data) {
+		switch {
+		case data[end] == skipS || data[end] == skipT:
+			end++
+			continue
+		case json5Space(data, end) > 0:
+			end += json5Space(data, end)
+			continue
+		case data[end] == division && end+1 < len(data) && data[end+1] == division:
+			if i := bytes.IndexByte(data[end:], skipN); i >= 0 {
+				end += i
+			} else {
+				end = len(data)
+			}
+		case data[end] == division && end+1 < len(data) && data[end+1] == asterisk:
+			next, err := skipComment(data, end)
+			if err != nil {
+				return index
+			}
+			end = next
+			continue
+		}
+		break
+	}
+	if end > index && end < len(data) && data[end-1] == skipR {
+		end--
+	}
+	if end == len(data) || data[end] == skipN || data[end] == skipR {
+		return end
+	}
+	return index
+}
+
+// keyEnd returns the end of the key of the object, which starts at the index.
+func keyEnd(data []byte, index int) int {
+	if index >= len(data) {
+		return index
+	}
+	if c := data[index]; c == quotes || c == quote {
+		for index++; index < len(data); index++ {
+			switch data[index] {
+			case backslash:
+				index++
+			case c:
+				return index + 1
+			}
+		}
+		return index
+	}
+	return identifierEnd(data, index)
+}
+
+// comments returns all comments in the trivia.
+func comments(data []byte) (result []string) {
+	for index := 0; index < len(data); {
+		if data[index] != division {
+			index++
+			continue
+		}
+		end, err := skipComment(data, index)
+		if err != nil {
+			break
+		}
+		result = append(result, string(bytes.TrimRight(data[index:end], "\r\n")))
+		index = end
+	}
+	return
+}
@@ -0,0 +1,357 @@
+package ajson
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+const jsoncExample = `// settings
+{
+	// the server
+	"host": "localhost", // default
+	"port": 8080,
+	/* tags */
+	"tags": [
+		"a",
+		"b", // second
+	],
+	"limits": {},
+	"debug": true // last
+}
+`
+
+func TestUnmarshalWithOptions_keepComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		mutate   func(root *Node) error
+		expected string
+	}{
+		{
+			name:     "untouched",
+			data:     jsoncExample,
+			mutate:   func(root *Node) error { return nil },
+			expected: jsoncExample,
+		},
+		{
+			name: "changed value",
+			data: jsoncExample,
+			mutate: func(root *Node) error {
+				return root.MustKey("port").SetNumeric(9090)
+			},
+			expected: `// settings
+{
+	// the server
+	"host": "localhost", // default
+	"port": 9090,
+	/* tags */
+	"tags": [
+		"a",
+		"b", // second
+	],
+	"limits": {},
+	"debug": true // last
+}
+`,
+		},
+		{
+			name: "deleted key",
+			data: jsoncExample,
+			mutate: func(root *Node) error {
+				return root.DeleteKey("host")
+			},
+			expected: `// settings
+{
+	"port": 8080,
+	/* tags */
+	"tags": [
+		"a",
+		"b", // second
+	],
+	"limits": {},
+	"debug": true // last
+}
+`,
+		},
+		{
+			name: "deleted last key",
+			data: jsoncExample,
+			mutate: func(root *Node) error {
+				return root.DeleteKey("debug")
+			},
+			expected: `// settings
+{
+	// the server
+	"host": "localhost", // default
+	"port": 8080,
+	/* tags */
+	"tags": [
+		"a",
+		"b", // second
+	],
+	"limits": {}
+}
+`,
+		},
+		{
+			name: "appended values",
+			data: jsoncExample,
+			mutate: func(root *Node) error {
+				if err := root.AppendObject("name", StringNode("", "example")); err != nil {
+					return err
+				}
+				if err := root.MustKey("limits").AppendObject("max", NumericNode("", 10)); err != nil {
+					return err
+				}
+				return root.MustKey("tags").AppendArray(StringNode("", "c"))
+			},
+			expected: `// settings
+{
+	// the server
+	"host": "localhost", // default
+	"port": 8080,
+	/* tags */
+	"tags": [
+		"a",
+		"b", // second
+		"c"
+	],
+	"limits": {"max":10},
+	"debug": true, // last
+	"name": "example"
+}
+`,
+		},
+		{
+			name: "replaced value",
+			data: jsoncExample,
+			mutate: func(root *Node) error {
+				return root.MustKey("tags").SetObject(nil)
+			},
+			expected: `// settings
+{
+	// the server
+	"host": "localhost", // default
+	"port": 8080,
+	/* tags */
+	"tags": {},
+	"limits": {},
+	"debug": true // last
+}
+`,
+		},
+		{
+			name: "compact array",
+			data: `[1, 2 /* two */, 3]`,
+			mutate: func(root *Node) error {
+				if err := root.DeleteIndex(0); err != nil {
+					return err
+				}
+				return root.AppendArray(NumericNode("", 4))
+			},
+			expected: `[2 /* two */, 3, 4]`,
+		},
+		{
+			name: "pop first",
+			data: `[1, 2, 3]`,
+			mutate: func(root *Node) error {
+				_, err := root.PopIndex(0)
+				return err
+			},
+			expected: `[2, 3]`,
+		},
+		{
+			name: "patch insert",
+			data: `[1, 2, 3]`,
+			mutate: func(root *Node) error {
+				return ApplyPatch(root, Must(Unmarshal([]byte(`[{"op": "add", "path": "/1", "value": 9}]`))))
+			},
+			expected: `[1, 9, 2, 3]`,
+		},
+		{
+			name: "patch insert first",
+			data: "{\"a\": [ 1,  2 ]}",
+			mutate: func(root *Node) error {
+				return ApplyPatch(root, Must(Unmarshal([]byte(`[{"op": "add", "path": "/a/0", "value": 0}]`))))
+			},
+			expected: "{\"a\": [ 0,  1,  2 ]}",
+		},
+		{
+			name: "multiline delete first",
+			data: "[\n\t1,\n\t// two\n\t2,\n\t3\n]",
+			mutate: func(root *Node) error {
+				return root.DeleteIndex(0)
+			},
+			expected: "[\n\t// two\n\t2,\n\t3\n]",
+		},
+		{
+			name: "line comment before the end",
+			data: "[1, // one\n2]",
+			mutate: func(root *Node) error {
+				return root.DeleteIndex(1)
+			},
+			expected: "[1 // one\n]",
+		},
+		{
+			name: "relaxed syntax",
+			data: `{a: 'b', c: [0x10, Infinity,], /* d */}`,
+			mutate: func(root *Node) error {
+				return root.MustKey("c").AppendArray(NumericNode("", 1))
+			},
+			expected: `{a: 'b', c: [0x10, Infinity, 1], /* d */}`,
+		},
+		{
+			name: "json5 whitespaces",
+			data: "\uFEFF{\u2028a: 1,\u2028b: 2\u2028}",
+			mutate: func(root *Node) error {
+				return root.MustKey("b").SetNumeric(3)
+			},
+			expected: "\uFEFF{\u2028a: 1,\u2028b: 3\u2028}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.data), ParseOptions{JSON5: true, KeepComments: true})
+			if err != nil {
+				t.Fatalf("UnmarshalWithOptions() error = %v", err)
+			}
+			if err = test.mutate(root); err != nil {
+				t.Fatalf("mutate() error = %v", err)
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("Marshal() = %s\nexpected %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_Comments(t *testing.T) {
+	root, err := UnmarshalWithOptions([]byte(jsoncExample), ParseOptions{KeepComments: true, AllowTrailingCommas: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		node     *Node
+		leading  []string
+		trailing []string
+	}{
+		{name: "root", node: root, leading: []string{"// settings"}},
+		{name: "key", node: root.MustKey("host"), leading: []string{"// the server"}, trailing: []string{"// default"}},
+		{name: "no comments", node: root.MustKey("port")},
+		{name: "block", node: root.MustKey("tags"), leading: []string{"/* tags */"}},
+		{name: "element", node: root.MustKey("tags").MustIndex(1), trailing: []string{"// second"}},
+		{name: "last", node: root.MustKey("debug"), trailing: []string{"// last"}},
+		{name: "not parsed", node: NullNode("")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leading, trailing := test.node.Comments()
+			if !reflect.DeepEqual(leading, test.leading) || !reflect.DeepEqual(trailing, test.trailing) {
+				t.Errorf("Comments() = %q, %q, expected %q, %q", leading, trailing, test.leading, test.trailing)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_keepCommentsEncoder(t *testing.T) {
+	root, err := UnmarshalWithOptions([]byte(jsoncExample), ParseOptions{KeepComments: true, AllowTrailingCommas: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	if err = root.MustKey("port").SetNumeric(1); err != nil {
+		t.Fatalf("SetNumeric() error = %v", err)
+	}
+	result, err := MarshalWithOptions(root, MarshalOptions{SortKeys: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() error = %v", err)
+	}
+	expected := `{"debug":true,"host":"localhost","limits":{},"port":1,"tags":["a","b"]}`
+	if string(result) != expected {
+		t.Errorf("MarshalWithOptions() = %s, expected %s", result, expected)
+	}
+	clone, err := Marshal(root.Clone())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if original, _ := Marshal(root); string(clone) != string(original) {
+		t.Errorf("Marshal() of the clone = %s, expected %s", clone, original)
+	}
+}
+
+func TestUnmarshalWithOptions_keepCommentsMutations(t *testing.T) {
+	options := ParseOptions{KeepComments: true, AllowTrailingCommas: true}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		root := Must(UnmarshalWithOptions([]byte(jsoncExample), options))
+		for j := random.Intn(4) + 1; j > 0; j-- {
+			nodes := append(recursiveChildren(root), root)
+			node := nodes[random.Intn(len(nodes))]
+			var err error
+			switch random.Intn(4) {
+			case 0:
+				err = node.SetNumeric(float64(j))
+			case 1:
+				err = node.Delete()
+			case 2:
+				if node.IsObject() {
+					err = node.AppendObject(fmt.Sprintf("key%d", j), NullNode(""))
+				} else if node.IsArray() {
+					err = node.AppendArray(NullNode(""))
+				}
+			default:
+				if node.parent != nil {
+					err = node.parent.replace(node, BoolNode("", true))
+				}
+			}
+			if err != nil {
+				t.Fatalf("mutation error = %v", err)
+			}
+		}
+		result, err := Marshal(root)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		parsed, err := UnmarshalWithOptions(result, options)
+		if err != nil {
+			t.Fatalf("UnmarshalWithOptions(%s) error = %v", result, err)
+		}
+		actual, _ := MarshalWithOptions(parsed, MarshalOptions{SortKeys: true})
+		expected, _ := MarshalWithOptions(root, MarshalOptions{SortKeys: true})
+		if string(actual) != string(expected) {
+			t.Fatalf("Marshal() = %s\nvalue %s, expected %s", result, actual, expected)
+		}
+	}
+}
+
+func ExampleNode_Comments() {
+	root, err := UnmarshalWithOptions([]byte(`{
+	// the server
+	"host": "localhost",
+	"port": 8080 // default
+}`), ParseOptions{KeepComments: true})
+	if err != nil {
+		panic(err)
+	}
+	leading, _ := root.MustKey("host").Comments()
+	_, trailing := root.MustKey("port").Comments()
+	fmt.Println(leading, trailing)
+	_ = root.MustKey("port").SetNumeric(9090)
+	_ = root.AppendObject("debug", BoolNode("", true))
+	result, _ := Marshal(root)
+	fmt.Printf("%s", result)
+	// Output:
+	// [// the server] [// default]
+	// {
+	// 	// the server
+	// 	"host": "localhost",
+	// 	"port": 9090, // default
+	// 	"debug": true
+	// }
+}